@@ -0,0 +1,66 @@
+package gioc
+
+import "fmt"
+
+// aliasOptions 注册别名的可选参数
+type aliasOptions struct {
+	// 别名已经存在并且类型不兼容时仍然覆盖
+	force bool
+}
+
+// AliasOption 注册别名的可选参数
+type AliasOption func(*aliasOptions)
+
+// ForceAlias 别名已经指向类型不兼容的 bean 时仍然覆盖，不返回 ErrAliasTypeMismatch
+func ForceAlias() AliasOption {
+	return func(opts *aliasOptions) {
+		opts.force = true
+	}
+}
+
+// RegisterBeanAlias 为已注册的 bean 注册别名，之后通过别名获取、注入的都是 beanName 对应的 bean
+// beanName 本身也可以是别名，别名会被解析为最终的 beanName；别名不能与已注册的 beanName 相同
+// 别名已经存在时，新 bean 的类型必须能够赋值给原来 bean 的类型，否则返回 ErrAliasTypeMismatch，
+// 避免 GetBean(alias).(*UserService) 之类依赖原有类型的代码失效，确实需要改变类型时使用 ForceAlias
+func (bc *BeanBeanFactory) RegisterBeanAlias(alias string, beanName string, opts ...AliasOption) error {
+	options := &aliasOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	if alias == "" {
+		return fmt.Errorf("alias of bean %v is empty", beanName)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if _, exist := bc.btMap[alias]; exist {
+		return fmt.Errorf("alias %v conflicts with a registered bean", alias)
+	}
+	target := bc.resolveAlias(beanName)
+	t, exist := bc.tMap[target]
+	if !exist {
+		return fmt.Errorf("alias %v: bean %v: %w", alias, beanName, ErrNotRegistered)
+	}
+	if old, exist := bc.aliasMap[alias]; exist && !options.force {
+		if oldT, ok := bc.tMap[old]; ok && !t.AssignableTo(oldT) {
+			return fmt.Errorf("alias %v: bean %v of type %v is not assignable to %v of bean %v: %w", alias, target, t, oldT, old, ErrAliasTypeMismatch)
+		}
+	}
+	bc.aliasMap[alias] = target
+	bc.invalidateDependents()
+	return nil
+}
+
+// resolveAlias 将别名解析为 beanName，不是别名时原样返回，调用方需要持有锁
+func (bc *BeanBeanFactory) resolveAlias(name string) string {
+	if beanName, exist := bc.aliasMap[name]; exist {
+		return beanName
+	}
+	return name
+}
+
+// canonicalName 将别名解析为 beanName，不是别名时原样返回
+func (bc *BeanBeanFactory) canonicalName(name string) string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.resolveAlias(name)
+}
@@ -0,0 +1,203 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// BeanDefinition bean 定义，Class 的导出版本，用于 bean 注册信息的序列化、传输
+type BeanDefinition struct {
+	// beanName
+	Name string
+	// bean 类型
+	Type reflect.Type
+	// bean 作用域
+	Scope BeanType
+	// 限定符
+	Qualifier Qualifier
+	// field 注入时使用的限定符，key 为 field 名
+	FieldQualifiers map[string]Qualifier
+	// 构造函数
+	Constructor interface{}
+	// 构造函数参数
+	ConstructorArgs []interface{}
+	// 提供者
+	Supplier func() interface{}
+	// 初始化方法名
+	InitMethod string
+	// 销毁方法名
+	DestroyMethod string
+	// 销毁优先级
+	DestroyPriority int
+	// 是否为首选 bean
+	Primary bool
+	// 属性值，key 为 field 名
+	Properties map[string]interface{}
+}
+
+// BeanDefinitionToClass 将 BeanDefinition 转换为用于注册的 Class
+func BeanDefinitionToClass(def BeanDefinition) (*Class, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("bean definition has no name")
+	}
+	// 存在构造函数时可以不指定类型，由构造函数返回值推导
+	if def.Type == nil && def.Constructor == nil {
+		return nil, fmt.Errorf("bean definition %v has no type", def.Name)
+	}
+	class := NewClass(def.Name, nil, def.Scope)
+	if def.Type != nil {
+		class.i = def.Type
+	}
+	class.qualifier = def.Qualifier.copy()
+	for fieldName, q := range def.FieldQualifiers {
+		class.SetFieldQualifier(fieldName, q.copy())
+	}
+	class.constructor = def.Constructor
+	class.constructorArgs = def.ConstructorArgs
+	class.supplier = def.Supplier
+	class.initMethod = def.InitMethod
+	class.destroyMethod = def.DestroyMethod
+	class.destroyPriority = def.DestroyPriority
+	class.primary = def.Primary
+	for name, value := range def.Properties {
+		class.SetProperty(name, value)
+	}
+	return class, nil
+}
+
+// ClassToBeanDefinition 将 Class 转换为 BeanDefinition
+// class 已经注册到 factory 时，Type 使用 factory 中维护的类型，否则从 class 中推导
+func ClassToBeanDefinition(class *Class, factory *BeanBeanFactory) (BeanDefinition, error) {
+	if class == nil {
+		return BeanDefinition{}, fmt.Errorf("class is nil")
+	}
+	def := BeanDefinition{
+		Name:            class.beanName,
+		Scope:           class.beanType,
+		Qualifier:       class.qualifier.copy(),
+		Constructor:     class.constructor,
+		ConstructorArgs: class.constructorArgs,
+		Supplier:        class.supplier,
+		InitMethod:      class.initMethod,
+		DestroyMethod:   class.destroyMethod,
+		DestroyPriority: class.destroyPriority,
+		Primary:         class.primary,
+	}
+	if class.fieldQualifiers != nil {
+		def.FieldQualifiers = map[string]Qualifier{}
+		for fieldName, q := range class.fieldQualifiers {
+			def.FieldQualifiers[fieldName] = q.copy()
+		}
+	}
+	if class.properties != nil {
+		def.Properties = map[string]interface{}{}
+		for name, value := range class.properties {
+			def.Properties[name] = value
+		}
+	}
+	if factory != nil {
+		factory.mu.RLock()
+		def.Type = factory.tMap[class.beanName]
+		factory.mu.RUnlock()
+	}
+	if def.Type == nil {
+		t, ok := class.i.(reflect.Type)
+		if !ok {
+			t = reflect.TypeOf(class.i)
+		}
+		def.Type = t
+	}
+	if def.Type == nil && class.constructor != nil {
+		if ct, err := checkConstructor(class.constructor, nil); err == nil {
+			def.Type = ct
+		}
+	}
+	if def.Type == nil {
+		return BeanDefinition{}, fmt.Errorf("bean %v has no type", class.beanName)
+	}
+	return def, nil
+}
+
+// MergeBeanDefinitions 合并两个 bean 定义，用于父子容器合并或者在基础定义上叠加覆盖配置，返回新的定义，不修改参数
+// 每个字段的合并规则如下，override 的字段为零值时表示不覆盖：
+//   - Name、Type、InitMethod、DestroyMethod：override 不为空时使用 override
+//   - Scope：override 不为 Invalid 时使用 override
+//   - Constructor、ConstructorArgs：override.Constructor 不为 nil 时两者一起使用 override，参数与构造函数一一对应，不能单独合并
+//   - Supplier：override 不为 nil 时使用 override
+//   - DestroyPriority：override 不为 0 时使用 override
+//   - Primary：任意一方为首选 bean 时结果为首选 bean
+//   - Qualifier：override.Name 不为空时使用 override.Name，Attributes 合并，key 相同时使用 override
+//   - FieldQualifiers：按 field 名合并，同一个 field 使用 override
+//   - Properties：按 field 名合并，同一个 field 使用 override
+//
+// 例如 base 为 {Name: "userDao", Type: *MysqlDao, Scope: Singleton, Properties: {"Table": "user", "Limit": 10}}，
+// override 为 {Scope: Prototype, Properties: {"Limit": 100}}，
+// 合并结果为 {Name: "userDao", Type: *MysqlDao, Scope: Prototype, Properties: {"Table": "user", "Limit": 100}}
+func MergeBeanDefinitions(base, override BeanDefinition) BeanDefinition {
+	merged := base
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if override.Type != nil {
+		merged.Type = override.Type
+	}
+	if override.Scope != Invalid {
+		merged.Scope = override.Scope
+	}
+	if override.Constructor != nil {
+		merged.Constructor = override.Constructor
+		merged.ConstructorArgs = override.ConstructorArgs
+	}
+	if override.Supplier != nil {
+		merged.Supplier = override.Supplier
+	}
+	if override.InitMethod != "" {
+		merged.InitMethod = override.InitMethod
+	}
+	if override.DestroyMethod != "" {
+		merged.DestroyMethod = override.DestroyMethod
+	}
+	if override.DestroyPriority != 0 {
+		merged.DestroyPriority = override.DestroyPriority
+	}
+	merged.Primary = base.Primary || override.Primary
+	merged.Qualifier = mergeQualifier(base.Qualifier, override.Qualifier)
+	merged.FieldQualifiers = nil
+	if base.FieldQualifiers != nil || override.FieldQualifiers != nil {
+		merged.FieldQualifiers = map[string]Qualifier{}
+		for fieldName, q := range base.FieldQualifiers {
+			merged.FieldQualifiers[fieldName] = q.copy()
+		}
+		for fieldName, q := range override.FieldQualifiers {
+			merged.FieldQualifiers[fieldName] = q.copy()
+		}
+	}
+	merged.Properties = nil
+	if base.Properties != nil || override.Properties != nil {
+		merged.Properties = map[string]interface{}{}
+		for name, value := range base.Properties {
+			merged.Properties[name] = value
+		}
+		for name, value := range override.Properties {
+			merged.Properties[name] = value
+		}
+	}
+	return merged
+}
+
+// mergeQualifier 合并限定符，override.Name 不为空时覆盖 Name，Attributes 按 key 合并
+func mergeQualifier(base, override Qualifier) Qualifier {
+	merged := base.copy()
+	if override.Name != "" {
+		merged.Name = override.Name
+	}
+	if len(override.Attributes) > 0 {
+		if merged.Attributes == nil {
+			merged.Attributes = map[string]string{}
+		}
+		for key, value := range override.Attributes {
+			merged.Attributes[key] = value
+		}
+	}
+	return merged
+}
@@ -1,20 +1,33 @@
 package gioc
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Bean 类型
 type BeanType string
 
 var (
-	// 无效类型，bean 没有注册或者没有标注正确的 bean 类型
+	// 无效类型，bean 没有注册或者没有标注正确的 bean 类型，注册时表示使用 WithDefaultBeanType 设置的默认类型
 	Invalid BeanType = ""
 	// 单例 bean
 	Singleton BeanType = "s"
 	// 原型 bean
 	Prototype BeanType = "p"
+	// 协程作用域 bean，同一个作用域令牌只会创建一次，只能通过 GetBeanScoped 获取
+	Goroutine BeanType = "g"
+	// 线程作用域 bean，通过 BorrowBean 从对象池借用，借用期间由调用方独占，GetBean 以及注入时与原型 bean 相同
+	Thread BeanType = "t"
+	// 键控作用域 bean，同一个 key 只会创建一次，只能通过 GetBeanKeyed 或者注入 func(key string) T 获取
+	Keyed BeanType = "k"
 )
 
 // BeanFactory bean 工厂接口
@@ -23,28 +36,188 @@ type BeanFactory interface {
 	Register(class *Class) error
 	// RegisterBeanProcessor 注册 bean 处理器
 	RegisterBeanProcessor(class *Class) error
+	// RegisterBeanProcessorFunc 注册只处理一个阶段的函数式 bean 处理器
+	RegisterBeanProcessorFunc(phase ProcessorPhase, fn func(beanName string, bean interface{}) interface{}) error
+	// Rebind 使用新的 bean 定义替换已注册的 bean
+	Rebind(def BeanDefinition) error
+	// RegisterInterfaceBean 注册一个由 supplier 提供实现的接口 bean
+	RegisterInterfaceBean(beanName string, ifaceType reflect.Type, supplier func() interface{}) error
+	// RegisterFromStructTags 按结构体的 gioc 注解批量注册 bean
+	RegisterFromStructTags(vals ...interface{}) error
+	// SetBeanScope 修改已注册 bean 的作用域
+	SetBeanScope(beanName string, newScope BeanType, opts ...SetScopeOption) error
+	// RegisterValueBean 将已经创建好的值注册为单例 bean
+	RegisterValueBean(beanName string, value interface{}) error
+	// RegisterScope 注册自定义作用域
+	RegisterScope(name string, scope Scope) error
+	// RegisterLazyProxy 注册接口的延迟代理工厂
+	RegisterLazyProxy(iface interface{}, factory LazyProxyFactory) error
+	// RegisterBeanAlias 为已注册的 bean 注册别名
+	RegisterBeanAlias(alias string, beanName string, opts ...AliasOption) error
+	// RegisterInterfaceAdvice 注册只在以接口注入时生效的通知
+	RegisterInterfaceAdvice(beanName string, iface interface{}, advice InterfaceAdvice) error
 	// GetBean 根据 beanName 获取 bean
 	GetBean(beanName string) interface{}
+	// GetBeanE 根据 beanName 获取 bean，关闭 failFast 时创建失败以 error 返回
+	GetBeanE(beanName string) (interface{}, error)
+	// GetBeanWithTimeout 获取 bean，超时返回 ErrTimeout
+	GetBeanWithTimeout(beanName string, timeout time.Duration) (interface{}, error)
+	// GetBeanContext 获取 bean，创建 bean 时使用 ctx
+	GetBeanContext(ctx context.Context, beanName string) (interface{}, error)
+	// GetBeanWithFallback 根据 beanName 获取 bean，bean 没有注册时使用 fallback
+	GetBeanWithFallback(beanName string, fallback func() interface{}) interface{}
+	// LookupBean 根据 beanName 获取 bean，同时返回 bean 是否已经注册
+	LookupBean(beanName string) (interface{}, bool)
+	// RegisterType 注册可以在 bean 描述文件中通过名称引用的类型
+	RegisterType(name string, i interface{}) error
+	// LoadDefinitions 从描述文件加载并注册 bean 定义
+	LoadDefinitions(r io.Reader, format string) error
+	// GetBeanSnapshot 获取 bean 的深拷贝
+	GetBeanSnapshot(beanName string) interface{}
+	// GetBeanScoped 根据作用域令牌获取 bean
+	GetBeanScoped(token interface{}, beanName string) (interface{}, error)
+	// ReleaseScoped 释放作用域令牌内的所有 bean
+	ReleaseScoped(token interface{})
+	// GetBeanKeyed 根据 key 获取 bean
+	GetBeanKeyed(key string, beanName string) (interface{}, error)
+	// ReleaseKeyed 释放 key 对应的所有 bean
+	ReleaseKeyed(key string)
+	// GetBeanAs 获取 bean 并赋值给 target 指向的变量
+	GetBeanAs(beanName string, target interface{}) error
+	// InjectInto 为未注册的外部对象注入依赖
+	InjectInto(target interface{}) error
+	// GetOrCreate bean 未注册时先注册再获取 bean
+	GetOrCreate(beanName string, class *Class) (interface{}, error)
+	// BorrowBean 从对象池借用线程作用域的 bean
+	BorrowBean(beanName string) (interface{}, func())
+	// ReleaseBean 归还原型 bean，开启原型池化时会被复用
+	ReleaseBean(beanName string, bean interface{})
+	// DestroyBean 销毁单例 bean
+	DestroyBean(beanName string)
+	// Decorate 为 bean 注册装饰器
+	Decorate(beanName string, decorator Decorator) error
+	// Rewire 为已经创建的单例 bean 补充注入零值 field
+	Rewire(beanName string) error
+	// EvictSingleton 丢弃缓存的单例 bean，下次获取时重新创建
+	EvictSingleton(beanName string) error
+	// Swap 使用新的实例替换单例 bean
+	Swap(beanName string, newInstance interface{}) error
+	// DestroySingletons 销毁所有单例 bean
+	DestroySingletons()
+	// InjectedDependencies 获取 bean 实际注入的依赖
+	InjectedDependencies(beanName string) map[string]string
+	// GetCreationOrder 获取单例 bean 第一次创建的顺序
+	GetCreationOrder() []string
+	// RegistrationOrder 按注册顺序获取已注册的 beanName
+	RegistrationOrder() []string
+	// StartupDuration 获取每个 bean 的创建耗时
+	StartupDuration() map[string]time.Duration
+	// Profile 执行 fn 并记录 bean 的创建树
+	Profile(fn func() error) (*CreationTree, error)
+	// ForEachSingleton 按创建顺序遍历已创建的单例 bean
+	ForEachSingleton(fn func(name string, bean interface{}) error) error
+	// ForEachSingletonConcurrent 并发遍历已创建的单例 bean
+	ForEachSingletonConcurrent(fn func(name string, bean interface{}) error) error
+	// GetBeanNameFor 根据单例 bean 实例反查 beanName
+	GetBeanNameFor(instance interface{}) (string, bool)
+	// GetBeansWhere 获取所有满足条件的 bean
+	GetBeansWhere(pred func(name string, attrs map[string]string, t reflect.Type) bool) map[string]interface{}
+	// GetAllBeanTypes 获取所有已注册 bean 的注册类型
+	GetAllBeanTypes() map[string]reflect.Type
+	// GetRegisteredBeanCount 获取已注册的 bean 数量
+	GetRegisteredBeanCount() int
+	// GetCreatedSingletonCount 获取已经创建的单例 bean 数量
+	GetCreatedSingletonCount() int
+	// GetBeanHolder 获取 bean 以及 bean 的元信息
+	GetBeanHolder(beanName string) (*BeanHolder, error)
+	// GetBeanHistogram 获取每个 bean 通过 GetBean 获取的次数
+	GetBeanHistogram() map[string]int
+	// Dependents 获取依赖 beanName 的所有 bean
+	Dependents(beanName string) []string
+	// GetBeanDependents 获取依赖 beanName 的所有 bean
+	GetBeanDependents(beanName string) []string
+	// GetBeanDependencies 获取 bean 直接依赖的 beanName
+	GetBeanDependencies(beanName string) []string
+	// GetBeanDependenciesTransitive 获取 bean 直接以及间接依赖的所有 beanName
+	GetBeanDependenciesTransitive(beanName string) ([]string, error)
+	// FindBeanDefinitionByType 获取注册类型能够赋值给 t 的所有 beanName
+	FindBeanDefinitionByType(t reflect.Type) []string
+	// ListBeans 按条件列出已注册的 bean 信息
+	ListBeans(filter BeanFilter) []BeanInfo
+	// ExportGraph 导出 bean 的依赖图
+	ExportGraph(w io.Writer, format string) error
+	// Validate 静态校验 bean 的装配是否正确
+	Validate() error
+	// Refresh 刷新 bean 工厂，提前创建所有单例 bean
+	Refresh() error
 	// getSingleton 获取单例 bean（这里以后学习 Spring 建立三级缓存解决循环依赖）
 	getSingleton(beanName string, allowEarlyReference bool) interface{}
 	// createBean 创建 bean 实例
-	createBean(beanName string, beanType BeanType, new bool) interface{}
+	createBean(ctx context.Context, beanName string, beanType BeanType, new bool) interface{}
+	// getOrCreateSingleton 获取单例 bean，不存在时创建并添加到单例池
+	getOrCreateSingleton(ctx context.Context, beanName string) interface{}
 	// addSingleton 添加单例 bean
 	addSingleton(beanName string, i interface{})
+	// isRegistered 判断 beanName 是否已经注册
+	isRegistered(beanName string) bool
 	// isAllowEarlyReference 是否允许循环依赖
 	isAllowEarlyReference() bool
+	// waitInflight 拒绝新的 GetBean 调用，并等待进行中的 GetBean 调用结束
+	waitInflight(ctx context.Context) error
+	// fireLifecyclePhase 通知容器生命周期监听器
+	fireLifecyclePhase(fn func(listener LifecyclePhaseListener))
 }
 
-// AutowiredTag 变量注入注解
+// AutowiredTag 变量注入注解，格式为 di:"beanName,选项..."，beanName 为空时按类型注入
 const AutowiredTag = "di"
 
+// ScopeTag 注入点作用域注解，格式为 scope:"s"，会覆盖注入 bean 声明的作用域
+const ScopeTag = "scope"
+
 // BeanNameTag 唯一标识 beanName 注解
+// Deprecated: 使用 di:"beanName"，只有 di 没有指定 beanName 时才会读取该注解
 const BeanNameTag = "beanName"
 
+// checkLegacyBeanNameTag 检查旧版本的 di:"s" beanName:"b" 写法，旧版本中 di 注解值为作用域，新版本中为 beanName，
+// 同时存在 beanName 注解和作用域标记时按新语义会注入名为 s 的 bean，因此返回迁移提示，避免静默注入错误的 bean
+func checkLegacyBeanNameTag(field reflect.StructField) error {
+	beanName, exist := field.Tag.Lookup(BeanNameTag)
+	if !exist {
+		return nil
+	}
+	autowireTag, _ := getAutowiredTag(field)
+	switch BeanType(autowireTag) {
+	case Singleton, Prototype, Goroutine:
+		return fmt.Errorf("field %v: legacy tag `di:%q beanName:%q`, di now names the bean, use `di:%q scope:%q` instead",
+			field.Name, autowireTag, beanName, beanName, autowireTag)
+	}
+	return nil
+}
+
+// ScopeContextTag 作用域 context 注入注解，格式为 scopectx:""，只能用于 context.Context 类型的 field
+// bean 必须属于实现了 ContextScope 的作用域，注入的是该作用域的 context
+const ScopeContextTag = "scopectx"
+
+// GuardTag 条件注入注解，格式为 diif:"caching.enabled"，属性源中该属性为 true 时才会注入，否则 field 保持零值
+const GuardTag = "diif"
+
+// ConfigPropsTag 配置属性绑定注解，格式为 configprops:"db"，将属性源中的 db.xxx 属性绑定到结构体或者结构体 ptr field
+const ConfigPropsTag = "configprops"
+
+// OrderTag field 注入顺序注解，格式为 order:"1"，同一个 bean 内按升序注入，没有该注解的 field 视为 0
+const OrderTag = "order"
+
+// DefaultMaxDepth bean 创建默认的最大嵌套深度
+const DefaultMaxDepth = 100
+
+// QualifierPrefix 变量注入注解中限定符的前缀
+const QualifierPrefix = "@qualifier="
+
 // initBeanProcessors 初始bean 处理器列表
 var initBeanProcessors = []func(*BeanBeanFactory) BeanProcessor{
 	NewPopulateBeanProcessor,
 	NewAopBeanProcessor,
+	NewValidateBeanProcessor,
 }
 
 // BeanBeanFactory bean 工厂实现
@@ -53,20 +226,80 @@ type BeanBeanFactory struct {
 	sc Container
 	// 维护原型 bean 容器
 	pc Container
+	// 维护自定义作用域 bean 容器
+	scMap map[BeanType]Container
+	// 注册表读写锁，保护 scMap、btMap、tMap、cMap、proxyMap、injectedMap
+	mu sync.RWMutex
 	// 维护所有注册 bean 的类型
 	btMap map[string]BeanType
 	// 维护所有注册 bean 的类型信息
 	tMap map[string]reflect.Type
+	// 维护所有注册 bean 的 Class 信息
+	cMap map[string]*Class
+	// 维护接口的延迟代理工厂
+	proxyMap map[reflect.Type]LazyProxyFactory
+	// 维护 bean 最近一次创建时每个 field 实际注入的 beanName
+	injectedMap map[string]map[string]string
+	// 维护 bean 的装饰器
+	decoratorMap map[string][]Decorator
+	// 维护 bean 的别名，key 为别名，value 为 beanName
+	aliasMap map[string]string
+	// 维护 bean 按注入接口生效的通知，key 为 beanName 和接口类型
+	adviceMap map[string]map[reflect.Type][]InterfaceAdvice
+	// 注册序号，每注册一个 bean 加一
+	registerSeq int
+	// 反向依赖索引，key 为 beanName，value 为依赖它的 beanName，第一次查询时构建，为 nil 表示需要重新构建
+	dependentsIndex map[string][]string
+	// 注册信息的版本，注册信息变化时加一，用于丢弃构建期间已经过期的反向依赖索引
+	registryVersion int
+	// 单例缓存锁，保护 singletonMap、earlyMap、factoryMap、creationOrder、creatingMap
+	smu sync.RWMutex
 	// 维护所有的单例 bean，一级缓存
 	singletonMap map[string]interface{}
 	// 维护早期暴露对象，用于解决循环依赖，二级缓存
 	earlyMap map[string]interface{}
 	// 工厂 map，三级缓存，用于 AOP bean
 	factoryMap map[string]func() interface{}
-	// 当前正在创建的 bean 列表
-	creatingMap map[string]interface{}
+	// 单例 bean 第一次添加到单例池的顺序
+	creationOrder []string
+	// 已经添加过单例池的 beanName，用于 creationOrder 去重
+	createdMap map[string]struct{}
+	// 单例池中当前实例的创建时间
+	createdAtMap map[string]time.Time
+	// 统计信息锁，保护 durationMap 和 profiler
+	statMu sync.Mutex
+	// bean 最近一次创建的耗时
+	durationMap map[string]time.Duration
+	// 进行中的 Profile，不存在时为 nil
+	profiler *creationProfiler
+	// bean 通过 GetBean 获取的次数，key 在注册时创建，由 mu 保护，计数本身通过 atomic 更新
+	getCountMap map[string]*int64
+	// 正在创建的单例 bean，由 smu 保护
+	creatingMap map[string]*singletonCreation
 	// bean 处理器集合
 	beanProcessors []BeanProcessor
+	// 作为 bean 处理器注册的 beanName
+	bpNames map[string]struct{}
+	// 原型 bean 对象池，key 为非 ptr 类型
+	poolMap sync.Map
+	// 维护可以通过名称加载的类型，用于从描述文件加载 bean 定义，由 mu 保护
+	typeRegistry map[string]reflect.Type
+	// 设置了注册条件、等待 Refresh 求值的 bean，由 mu 保护
+	conditionalClasses []*Class
+	// 协程作用域 bean 的缓存
+	tokenScope *tokenScope
+	// 键控作用域 bean 的缓存，key 作为作用域令牌
+	keyedScope *tokenScope
+	// 线程作用域 bean 的对象池，key 为 beanName
+	threadPools sync.Map
+	// 关闭锁，保护 closed、inflight、idle
+	cmu sync.Mutex
+	// 是否已经开始 GracefulShutdown，开始后新的 GetBean 调用返回 ErrClosed，避免销毁后重新创建单例
+	closed bool
+	// 进行中的 GetBean 调用数量，GracefulShutdown 等待其归零后再销毁单例
+	inflight int
+	// inflight 归零时关闭，由 waitInflight 创建
+	idle chan struct{}
 	// 可选参数
 	opts *Options
 }
@@ -74,13 +307,28 @@ type BeanBeanFactory struct {
 // NewBeanFactory 实例化一个 bean 工厂
 func NewBeanFactory(opts ...Option) BeanFactory {
 	bc := &BeanBeanFactory{
+		scMap:        map[BeanType]Container{},
 		btMap:        map[string]BeanType{},
 		tMap:         map[string]reflect.Type{},
+		cMap:         map[string]*Class{},
+		proxyMap:     map[reflect.Type]LazyProxyFactory{},
+		typeRegistry: map[string]reflect.Type{},
+		injectedMap:  map[string]map[string]string{},
+		decoratorMap: map[string][]Decorator{},
+		adviceMap:    map[string]map[reflect.Type][]InterfaceAdvice{},
+		aliasMap:     map[string]string{},
 		singletonMap: map[string]interface{}{},
 		earlyMap:     map[string]interface{}{},
 		factoryMap:   map[string]func() interface{}{},
-		creatingMap:  map[string]interface{}{},
-		opts:         &Options{},
+		createdMap:   map[string]struct{}{},
+		createdAtMap: map[string]time.Time{},
+		bpNames:      map[string]struct{}{},
+		durationMap:  map[string]time.Duration{},
+		getCountMap:  map[string]*int64{},
+		creatingMap:  map[string]*singletonCreation{},
+		tokenScope:   newTokenScope(),
+		keyedScope:   newTokenScope(),
+		opts:         &Options{failFast: true, maxDepth: DefaultMaxDepth},
 	}
 	bc.sc = NewSingletonContainer(bc)
 	bc.pc = NewPrototypeContainer(bc)
@@ -96,28 +344,183 @@ func NewBeanFactory(opts ...Option) BeanFactory {
 }
 
 // Register 注册一个 bean 到 beanFactory 中
+// 不允许暴露早期对象时，会静态检查新注册的 bean 是否与已注册的 bean 构成循环依赖，构成循环依赖时拒绝注册
+// 设置了注册条件的 bean 会暂存起来，在 Refresh 时满足条件才会真正注册，注册错误也由 Refresh 返回
 func (bc *BeanBeanFactory) Register(class *Class) error {
+	if len(class.conditions) > 0 {
+		bc.addConditionalClass(class)
+		return nil
+	}
+	bc.mu.Lock()
+	err := bc.doRegister(class)
+	bc.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if !bc.isAllowEarlyReference() {
+		if cycle := bc.findCycleFrom(class.beanName); cycle != nil {
+			bc.unregister(class.beanName)
+			return fmt.Errorf("%w: %v", ErrWouldCreateCircularDependency, strings.Join(cycle, " -> "))
+		}
+	}
+	if bc.opts.devMode {
+		if err := bc.checkNamedDependencies(class.beanName); err != nil {
+			bc.unregister(class.beanName)
+			return err
+		}
+	}
+	bc.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+		listener.OnBeanRegistered(class.beanName)
+	})
+	return nil
+}
+
+// doRegister 真正的注册逻辑，调用方需要持有写锁
+func (bc *BeanBeanFactory) doRegister(class *Class) error {
 	beanName := class.beanName
 	beanType := class.beanType
+	// 没有指定 bean 类型时使用默认类型
+	if beanType == Invalid {
+		beanType = bc.opts.defaultBeanType
+	}
 	i := class.i
-	if !isSingleton(beanType) && !isPrototype(beanType) {
+	if !bc.isValidBeanType(beanType) {
 		return fmt.Errorf("beanType: %v 不符合要求\n", beanType)
 	}
 	// 判断 beanName 是否已经注册过了，因为 beanName 是唯一标识，所以不能重复
-	if bc.isRegistered(beanName) {
+	if _, exist := bc.tMap[beanName]; exist {
 		return fmt.Errorf("beanName was registered by other bean")
 	}
+	if _, exist := bc.aliasMap[beanName]; exist {
+		return fmt.Errorf("beanName %v was registered as an alias", beanName)
+	}
 	var t reflect.Type
 	t, ok := i.(reflect.Type)
 	if !ok {
 		// 这里不调用 Elem()，因为可能注册的就是一个指针类型，因此这里不做指针处理
 		t = reflect.TypeOf(i)
 	}
+	// 存在构造函数，校验构造函数的合法性
+	if class.constructor != nil {
+		ct, err := checkConstructor(class.constructor, t)
+		if err != nil {
+			return fmt.Errorf("bean %v: %v", beanName, err)
+		}
+		// 指定了参数，校验参数个数
+		if len(class.constructorArgs) > 0 {
+			if err := checkConstructorArgs(reflect.TypeOf(class.constructor), len(class.constructorArgs)); err != nil {
+				return fmt.Errorf("bean %v: %v", beanName, err)
+			}
+		}
+		// 没有指定 bean 类型，那么使用构造函数返回值类型
+		t = ct
+	}
+	if t == nil {
+		return fmt.Errorf("bean %v has no type", beanName)
+	}
+	if class.initMethod != "" {
+		if err := checkLifecycleMethod(t, class.initMethod); err != nil {
+			return fmt.Errorf("bean %v: %v", beanName, err)
+		}
+	}
+	if class.destroyMethod != "" {
+		if err := checkLifecycleMethod(t, class.destroyMethod); err != nil {
+			return fmt.Errorf("bean %v: %v", beanName, err)
+		}
+	}
+	for name, value := range class.properties {
+		if _, err := checkProperty(t, name, value); err != nil {
+			return fmt.Errorf("bean %v: %v", beanName, err)
+		}
+	}
 	bc.btMap[beanName] = beanType
 	bc.tMap[beanName] = t
+	bc.getCountMap[beanName] = new(int64)
+	// 这里复制一份，避免注册后外部修改 class 影响 bean 的创建
+	c := *class
+	c.beanType = beanType
+	if class.properties != nil {
+		c.properties = map[string]interface{}{}
+		for name, value := range class.properties {
+			c.properties[name] = value
+		}
+	}
+	c.qualifier = class.qualifier.copy()
+	if class.fieldQualifiers != nil {
+		c.fieldQualifiers = map[string]Qualifier{}
+		for fieldName, q := range class.fieldQualifiers {
+			c.fieldQualifiers[fieldName] = q.copy()
+		}
+	}
+	c.order = bc.registerSeq
+	bc.registerSeq++
+	bc.cMap[beanName] = &c
+	bc.invalidateDependents()
 	return nil
 }
 
+// Rebind 使用新的 bean 定义替换已注册的 bean，已创建的单例会被销毁，下次获取时按新的定义创建
+// beanName 未注册时直接注册
+func (bc *BeanBeanFactory) Rebind(def BeanDefinition) error {
+	class, err := BeanDefinitionToClass(def)
+	if err != nil {
+		return err
+	}
+	bc.mu.Lock()
+	oldType, exist := bc.btMap[def.Name]
+	oldT, oldClass, oldCount := bc.tMap[def.Name], bc.cMap[def.Name], bc.getCountMap[def.Name]
+	bc.removeBeanDefinition(def.Name)
+	err = bc.doRegister(class)
+	if err != nil {
+		// 注册失败，恢复原来的定义
+		if exist {
+			bc.btMap[def.Name], bc.tMap[def.Name], bc.cMap[def.Name] = oldType, oldT, oldClass
+			bc.getCountMap[def.Name] = oldCount
+			bc.invalidateDependents()
+		}
+		bc.mu.Unlock()
+		return err
+	}
+	// 保持原来的注册顺序
+	if exist {
+		bc.cMap[def.Name].order = oldClass.order
+		bc.getCountMap[def.Name] = oldCount
+	}
+	bc.mu.Unlock()
+	bc.DestroyBean(def.Name)
+	return nil
+}
+
+// unregister 移除 bean 定义并销毁已创建的单例 bean
+func (bc *BeanBeanFactory) unregister(beanName string) {
+	bc.mu.Lock()
+	bc.removeBeanDefinition(beanName)
+	delete(bc.decoratorMap, beanName)
+	delete(bc.adviceMap, beanName)
+	bc.mu.Unlock()
+	bc.DestroyBean(beanName)
+}
+
+// removeBeanDefinition 移除 bean 定义，调用方需要持有写锁
+func (bc *BeanBeanFactory) removeBeanDefinition(beanName string) {
+	delete(bc.btMap, beanName)
+	delete(bc.tMap, beanName)
+	delete(bc.cMap, beanName)
+	delete(bc.injectedMap, beanName)
+	delete(bc.getCountMap, beanName)
+	bc.invalidateDependents()
+}
+
+// getBeanDefinition 获取已注册 bean 的定义
+func (bc *BeanBeanFactory) getBeanDefinition(beanName string) (BeanDefinition, bool) {
+	class := bc.getClass(beanName)
+	if class == nil {
+		return BeanDefinition{}, false
+	}
+	def, err := ClassToBeanDefinition(class, bc)
+	return def, err == nil
+}
+
 // RegisterBeanProcessor 注册 bean 处理器
 func (bc *BeanBeanFactory) RegisterBeanProcessor(class *Class) error {
 	class.beanType = Singleton
@@ -125,22 +528,239 @@ func (bc *BeanBeanFactory) RegisterBeanProcessor(class *Class) error {
 	if err != nil {
 		return err
 	}
-	bpBean := bc.GetBean(class.beanName)
+	bpBean, err := bc.getBeanE(class.beanName)
 	bp, ok := bpBean.(BeanProcessor)
-	if !ok {
-		bc.tMap = nil
-		bc.singletonMap = nil
-		delete(bc.btMap, class.beanName)
+	if err != nil || !ok {
+		bc.unregister(class.beanName)
+		if err != nil {
+			return err
+		}
 		return fmt.Errorf("bean %v is not a bean processor", class.beanName)
 	}
-	bc.beanProcessors = append(bc.beanProcessors, bp)
+	bc.addBeanProcessor(class.beanName, bp)
 	return nil
 }
 
-// GetBean 根据 beanName 获取 bean 实例
+// RegisterValueBean 将已经创建好的值注册为单例 bean，值可以是任意类型，例如 os.Stdout、*sql.DB、配置字符串
+// 值直接放入单例缓存，获取时不经过 createBean，也不会进行属性注入以及 bean 处理器的处理
+// 非结构体、接口、map、slice 类型的值只能按 beanName 注入，例如 di:"appName"
+func (bc *BeanBeanFactory) RegisterValueBean(beanName string, value interface{}) error {
+	if value == nil {
+		return fmt.Errorf("value of bean %v is nil", beanName)
+	}
+	// 提供者返回同一个值，单例被销毁后再次获取时仍然是该值
+	class := NewClass(beanName, reflect.TypeOf(value), Singleton).SetSupplier(func() interface{} {
+		return value
+	})
+	if err := bc.Register(class); err != nil {
+		return err
+	}
+	bc.addSingleton(beanName, value)
+	return nil
+}
+
+// RegisterInterfaceBean 注册一个由 supplier 提供实现的接口单例 bean，接口类型的 field 可以注入该 bean
+// supplier 返回值是否实现了 ifaceType 会在 bean 第一次创建时校验
+func (bc *BeanBeanFactory) RegisterInterfaceBean(beanName string, ifaceType reflect.Type, supplier func() interface{}) error {
+	if ifaceType == nil || ifaceType.Kind() != reflect.Interface {
+		return fmt.Errorf("bean %v: %v is not an interface", beanName, ifaceType)
+	}
+	if supplier == nil {
+		return fmt.Errorf("bean %v: supplier is nil", beanName)
+	}
+	return bc.Register(NewClass(beanName, ifaceType, Singleton).SetSupplier(supplier))
+}
+
+// RegisterScope 注册自定义作用域，注册后 name 可以作为 BeanType 使用
+func (bc *BeanBeanFactory) RegisterScope(name string, scope Scope) error {
+	beanType := BeanType(name)
+	// 内置作用域不允许覆盖
+	if beanType == Invalid || isSingleton(beanType) || isPrototype(beanType) || isGoroutine(beanType) || isThread(beanType) || isKeyed(beanType) {
+		return fmt.Errorf("scope %v is reserved", name)
+	}
+	if scope == nil {
+		return fmt.Errorf("scope %v is nil", name)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if bc.isScope(beanType) {
+		return fmt.Errorf("scope %v was registered", name)
+	}
+	bc.scMap[beanType] = NewScopeContainer(bc, beanType, scope)
+	return nil
+}
+
+// GetBean 根据 beanName 获取 bean 实例，bean 没有注册返回 nil，关闭 failFast 时创建失败会打印错误并返回 nil
 func (bc *BeanBeanFactory) GetBean(beanName string) interface{} {
-	// 获取 bean 类型
-	return bc.doGetBean(beanName, false)
+	bean, err := bc.GetBeanE(beanName)
+	if errors.Is(err, ErrNotRegistered) {
+		return nil
+	}
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	return bean
+}
+
+// GetBeanE 根据 beanName 获取 bean 实例，bean 没有注册返回 ErrNotRegistered
+// 开启 failFast 时（默认）创建失败直接 panic，关闭时将 panic 转换为 error 返回
+func (bc *BeanBeanFactory) GetBeanE(beanName string) (interface{}, error) {
+	bc.countGet(beanName)
+	return bc.getBeanE(beanName)
+}
+
+// getBeanE 与 GetBeanE 相同，但是不计入获取次数，用于容器内部获取 bean
+func (bc *BeanBeanFactory) getBeanE(beanName string) (bean interface{}, err error) {
+	if bc.getBeanType(beanName) == Invalid {
+		return nil, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if err := bc.acquireCall(beanName); err != nil {
+		return nil, err
+	}
+	defer bc.releaseCall()
+	defer bc.recoverCreatePanic(&err)
+	bean = bc.doGetBean(beanName, false)
+	return bean, nil
+}
+
+// GetBeanWithTimeout 根据 beanName 获取 bean 实例，超过 timeout 没有创建完成时返回 ErrTimeout
+// bean 在后台 goroutine 中继续创建，创建成功的单例 bean 仍然会被缓存；后台 goroutine 中的 panic 总是转换为 error，不受 failFast 影响
+func (bc *BeanBeanFactory) GetBeanWithTimeout(beanName string, timeout time.Duration) (interface{}, error) {
+	type result struct {
+		bean interface{}
+		err  error
+	}
+	// 带缓冲，超时后后台 goroutine 也能正常退出
+	ch := make(chan result, 1)
+	go func() {
+		var res result
+		defer func() {
+			if r := recover(); r != nil {
+				res.err = toError(r)
+			}
+			ch <- res
+		}()
+		res.bean, res.err = bc.GetBeanE(beanName)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case res := <-ch:
+		return res.bean, res.err
+	case <-timer.C:
+		return nil, fmt.Errorf("bean %v: %w after %v", beanName, ErrTimeout, timeout)
+	}
+}
+
+// recoverCreatePanic 关闭 failFast 时将 bean 创建过程中的 panic 转换为 error，必须通过 defer 调用
+// 这里是 bean 创建 panic 唯一的 recover 位置，内部嵌套获取 bean 都走 doGetBean，保证错误能够传递到最外层
+func (bc *BeanBeanFactory) recoverCreatePanic(err *error) {
+	if bc.opts.failFast {
+		return
+	}
+	if r := recover(); r != nil {
+		*err = toError(r)
+	}
+}
+
+// GetBeanWithFallback 根据 beanName 获取 bean 实例，只有 bean 没有注册时才使用 fallback 的返回值
+// bean 已经注册但是创建失败时不会使用 fallback，也不会吞掉错误：开启 failFast 时创建失败直接 panic，
+// 关闭 failFast 时由于没有 error 返回值，同样以创建失败的 error panic，调用方需要 recover 或者改用 GetBeanE
+func (bc *BeanBeanFactory) GetBeanWithFallback(beanName string, fallback func() interface{}) interface{} {
+	if !bc.isRegistered(beanName) {
+		if fallback == nil {
+			return nil
+		}
+		return fallback()
+	}
+	bean, err := bc.GetBeanE(beanName)
+	if err != nil {
+		panic(err)
+	}
+	return bean
+}
+
+// LookupBean 根据 beanName 获取 bean 实例，第二个返回值表示 bean 是否已经注册，类似 map 的访问方式
+// 用于区分 bean 没有注册和已注册但是得到 nil 的情况，例如关闭 failFast 时创建失败，此时会打印错误并返回 nil, true
+func (bc *BeanBeanFactory) LookupBean(beanName string) (interface{}, bool) {
+	if !bc.isRegistered(beanName) {
+		return nil, false
+	}
+	bean, err := bc.GetBeanE(beanName)
+	if err != nil {
+		fmt.Println(err)
+		return nil, true
+	}
+	return bean, true
+}
+
+// GetBeanAs 获取 bean 并赋值给 target 指向的变量，target 必须是非 nil 指针，例如 var a *A; GetBeanAs("a", &a)
+// bean 不能赋值给 target 指向的类型时返回 ErrTypeMismatch，避免类型断言失败导致 panic
+func (bc *BeanBeanFactory) GetBeanAs(beanName string, target interface{}) error {
+	targetV := reflect.ValueOf(target)
+	if targetV.Kind() != reflect.Ptr || targetV.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	bean, err := bc.GetBeanE(beanName)
+	if err != nil {
+		return err
+	}
+	beanV := reflect.ValueOf(bean)
+	elem := targetV.Elem()
+	if !beanV.IsValid() || !beanV.Type().AssignableTo(elem.Type()) {
+		return fmt.Errorf("bean %v of type %T can not be assigned to %v: %w", beanName, bean, elem.Type(), ErrTypeMismatch)
+	}
+	elem.Set(beanV)
+	return nil
+}
+
+// InjectInto 为未注册到容器的外部对象注入依赖，target 必须是结构体指针，类似 Spring 的 autowireBean
+// target 本身不会被注册，也不会经过 bean 的初始化流程，只执行属性注入
+func (bc *BeanBeanFactory) InjectInto(target interface{}) (err error) {
+	targetV := reflect.ValueOf(target)
+	if targetV.Kind() != reflect.Ptr || targetV.IsNil() || targetV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("inject target must be a non-nil struct pointer, got %T", target)
+	}
+	defer bc.recoverCreatePanic(&err)
+	NewPopulateBeanProcessor(bc).processPropertyValues(context.Background(), "", targetV.Elem(), targetV.Elem().Type())
+	return nil
+}
+
+// GetOrCreate 如果 beanName 没有注册，那么先注册 class，再获取 bean
+// 检查和注册在同一把写锁内完成，避免并发重复注册
+func (bc *BeanBeanFactory) GetOrCreate(beanName string, class *Class) (interface{}, error) {
+	registered := false
+	err := func() error {
+		bc.mu.Lock()
+		defer bc.mu.Unlock()
+		if _, exist := bc.btMap[beanName]; exist {
+			// 开发模式下同名 bean 的类型不同视为重复定义
+			if bc.opts.devMode && class != nil {
+				if t := getClassType(class); t != nil && t != bc.tMap[beanName] {
+					return fmt.Errorf("bean %v was registered with type %v, got %v", beanName, bc.tMap[beanName], t)
+				}
+			}
+			return nil
+		}
+		if class == nil || class.beanName != beanName {
+			return fmt.Errorf("class of bean %v not match", beanName)
+		}
+		if err := bc.doRegister(class); err != nil {
+			return err
+		}
+		registered = true
+		return nil
+	}()
+	if err != nil {
+		return nil, err
+	}
+	if registered {
+		bc.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+			listener.OnBeanRegistered(beanName)
+		})
+	}
+	return bc.GetBeanE(beanName)
 }
 
 // GetNewBean 根据 beanName 获取 bean 实例
@@ -149,15 +769,15 @@ func (bc *BeanBeanFactory) GetNewBean(beanName string) interface{} {
 	return bc.doGetBean(beanName, true)
 }
 
-// GetBean 根据 beanName 获取 bean 实例
+// doGetBean 根据 beanName 获取 bean 实例，创建失败时 panic
 func (bc *BeanBeanFactory) doGetBean(beanName string, new bool) interface{} {
-	// 处理 createBean 抛出的 panic
-	defer func() {
-		if err := recover(); err != nil {
-			fmt.Println(err)
-			panic(err)
-		}
-	}()
+	return bc.doGetBeanContext(context.Background(), beanName, new)
+}
+
+// doGetBeanContext 根据 beanName 获取 bean 实例，需要创建 bean 时将 ctx 传递给创建过程，创建失败时 panic
+func (bc *BeanBeanFactory) doGetBeanContext(ctx context.Context, beanName string, new bool) interface{} {
+	// 别名统一解析为 beanName，缓存以及创建都使用 beanName
+	beanName = bc.canonicalName(beanName)
 	// 获取 bean 类型
 	beanType := bc.getBeanType(beanName)
 	// bean 不存在
@@ -166,37 +786,94 @@ func (bc *BeanBeanFactory) doGetBean(beanName string, new bool) interface{} {
 	}
 	var bean interface{}
 	if isSingleton(beanType) {
-		bean = bc.sc.Get(beanName, new)
+		bean = getFromContainer(ctx, bc.sc, beanName, new)
+	} else if isPrototype(beanType) {
+		bean = getFromContainer(ctx, bc.pc, beanName, new)
+	} else if isThread(beanType) {
+		// 不经过对象池直接获取时，与原型 bean 相同
+		bean = bc.createBean(ctx, beanName, Thread, new)
+	} else if isGoroutine(beanType) {
+		// 没有作用域令牌，无法确定使用哪个实例
+		panic(fmt.Errorf("bean %v is goroutine scoped, use GetBeanScoped", beanName))
+	} else if isKeyed(beanType) {
+		// 没有 key，无法确定使用哪个实例
+		panic(fmt.Errorf("bean %v is keyed scoped, use GetBeanKeyed or inject func(key string) T", beanName))
 	} else {
-		bean = bc.pc.Get(beanName, new)
+		bc.mu.RLock()
+		container := bc.scMap[beanType]
+		bc.mu.RUnlock()
+		bean = getFromContainer(ctx, container, beanName, new)
 	}
 	return bean
 }
 
+// getFromContainer 从容器中获取 bean，容器支持时将 ctx 传递给创建过程
+func getFromContainer(ctx context.Context, container Container, beanName string, new bool) interface{} {
+	if c, ok := container.(contextContainer); ok {
+		return c.getContext(ctx, beanName, new)
+	}
+	return container.Get(beanName, new)
+}
+
+// getBeanWithScope 按注入点指定的作用域获取 bean，注入点的作用域会覆盖 bean 声明的作用域
+// scope:"p" 每次都创建一个新的 bean，即使 bean 声明为单例；scope:"s" 获取共享的单例，即使 bean 声明为原型
+// new 为 true 时总是创建新的 bean，用于非 ptr 结构体注入
+func (bc *BeanBeanFactory) getBeanWithScope(ctx context.Context, beanName string, beanType BeanType, new bool) interface{} {
+	if bc.getBeanType(beanName) == Invalid {
+		return nil
+	}
+	if isPrototype(beanType) {
+		return bc.doGetBeanContext(ctx, beanName, true)
+	}
+	if isSingleton(beanType) {
+		return getFromContainer(ctx, bc.sc, bc.canonicalName(beanName), new)
+	}
+	return bc.doGetBeanContext(ctx, beanName, new)
+}
+
 // createBean 创建 bean 实例
-func (bc *BeanBeanFactory) createBean(beanName string, beanType BeanType, new bool) interface{} {
-	if !new {
-		// bean 创建的前置处理
-		bc.createBefore(beanName, beanType)
-		// bean 创建完毕的后置处理
-		defer bc.createAfter(beanName, beanType)
+// ctx 会传递给构造函数以及 InitializingBean.AfterPropertiesSet
+func (bc *BeanBeanFactory) createBean(ctx context.Context, beanName string, beanType BeanType, new bool) (bean interface{}) {
+	// 记录创建耗时，包含依赖 bean 的创建耗时，创建失败不记录
+	node := bc.profileEnter(beanName)
+	start := time.Now()
+	defer func() {
+		d := time.Since(start)
+		if bean != nil {
+			bc.recordDuration(beanName, d)
+		}
+		bc.profileExit(node, d, bean != nil)
+	}()
+	// 依赖 bean 与当前 bean 属于同一条创建链，嵌套创建的深度按创建链计数，并发的创建互不影响
+	ctx, chain := getCreationChain(ctx)
+	chain.depth++
+	defer func() { chain.depth-- }()
+	if bc.opts.maxDepth > 0 && chain.depth > bc.opts.maxDepth {
+		panic(fmt.Errorf("bean %v: %w: %v", beanName, ErrMaxDepthExceeded, bc.opts.maxDepth))
 	}
 	// 获取 bean 类型信息
+	bc.mu.RLock()
 	t, exist := bc.tMap[beanName]
+	bc.mu.RUnlock()
 	if !exist {
 		return nil
 	}
 	// 创建 bean 前看该 bean 是否存在特殊创建逻辑
-	bean := bc.resolveBeforeInstantiation(beanName, t)
+	bean = bc.resolveBeforeInstantiation(beanName, t)
 	if bean != nil {
 		return bean
 	}
+	// 存在提供者，直接使用提供者返回的实例
+	if class := bc.getClass(beanName); class != nil && class.supplier != nil {
+		return bc.createBeanWithSupplier(ctx, beanName, class.supplier, t)
+	}
 	// 创建 bean
-	return bc.doCreateBean(beanName, t)
+	// 只有需要缓存的单例 bean 才暴露早期对象，原型 bean 和全新创建的 bean 不会进入单例缓存
+	return bc.doCreateBean(ctx, beanName, t, isSingleton(beanType) && !new && bc.isAllowEarlyReference())
 }
 
-// doCreateBean 真正的创建 bean 实例逻辑
-func (bc *BeanBeanFactory) doCreateBean(beanName string, tPtr reflect.Type) interface{} {
+// doCreateBean 真正的创建 bean 实例逻辑，earlyExpose 表示是否暴露早期对象用于解决循环依赖
+func (bc *BeanBeanFactory) doCreateBean(ctx context.Context, beanName string, tPtr reflect.Type, earlyExpose bool) interface{} {
 	// 非 ptr type
 	var t reflect.Type
 	if tPtr.Kind() == reflect.Ptr {
@@ -209,12 +886,12 @@ func (bc *BeanBeanFactory) doCreateBean(beanName string, tPtr reflect.Type) inte
 		return nil
 	}
 	// 创建实例
-	beanPtr := reflect.New(t)
+	beanPtr := bc.instantiateBean(ctx, beanName, t)
 	// 非 ptr bean value
 	bean := beanPtr.Elem()
 
 	// 判断是否允许暴露早期对象
-	if bc.opts.allowEarlyReference {
+	if earlyExpose {
 		if t == tPtr {
 			// 非 ptr bean
 			bc.addSingletonFactory(beanName, bean.Interface(), t)
@@ -223,8 +900,13 @@ func (bc *BeanBeanFactory) doCreateBean(beanName string, tPtr reflect.Type) inte
 			bc.addSingletonFactory(beanName, beanPtr.Interface(), t)
 		}
 	}
+	// 属性赋值
+	bc.applyProperties(beanName, bean)
 	// 属性注入
-	bc.populateBean(bean, t)
+	bc.populateBean(ctx, beanName, bean, t)
+	// 调用初始化方法
+	bc.invokeAfterPropertiesSet(ctx, beanName, beanPtr.Interface())
+	bc.invokeInitMethod(beanName, beanPtr)
 
 	// 初始化 bean，这里会执行 AOP 处理
 	// 注意这里需要传入 ptr bean，为了跟下面的 getSingleton 对齐
@@ -239,8 +921,8 @@ func (bc *BeanBeanFactory) doCreateBean(beanName string, tPtr reflect.Type) inte
 	// 	1、如果 A 没有暴露早期对象或者没有循环依赖，那么 bean2 就是最终需要返回的 bean
 	// 	2、如果 A 存在循环依赖，那么 bean3 就是最终需要返回的 bean
 	var resBean interface{}
-	// 允许循环依赖
-	if bc.isAllowEarlyReference() {
+	// 暴露了早期对象
+	if earlyExpose {
 		// 判断是否出现了循环依赖
 		// 这里的 resBean 就是上面讲的 bean3
 		resBean = bc.getSingleton(beanName, false)
@@ -266,11 +948,108 @@ func (bc *BeanBeanFactory) doCreateBean(beanName string, tPtr reflect.Type) inte
 	return resBean
 }
 
+// instantiateBean 实例化 bean，存在构造函数时调用构造函数，否则直接 reflect.New
+// 返回的都是 ptr bean value
+func (bc *BeanBeanFactory) instantiateBean(ctx context.Context, beanName string, t reflect.Type) reflect.Value {
+	class := bc.getClass(beanName)
+	if class == nil || class.constructor == nil {
+		return bc.newBean(beanName, t)
+	}
+	bean, err := bc.callConstructor(ctx, class.constructor, class.constructorArgs)
+	if err != nil {
+		panic(fmt.Errorf("create bean %v failed: %w", beanName, err))
+	}
+	if bean.Kind() == reflect.Ptr {
+		return bean
+	}
+	// 构造函数返回的是非 ptr bean，这里转换为 ptr bean
+	beanPtr := reflect.New(t)
+	beanPtr.Elem().Set(bean)
+	return beanPtr
+}
+
+// newBean 实例化 bean，开启原型池化时优先从对象池中获取已重置的实例
+// map 和 slice bean 创建为空的 map 和 slice，而不是 nil，不参与池化
+func (bc *BeanBeanFactory) newBean(beanName string, t reflect.Type) reflect.Value {
+	switch t.Kind() {
+	case reflect.Map:
+		beanPtr := reflect.New(t)
+		beanPtr.Elem().Set(reflect.MakeMap(t))
+		return beanPtr
+	case reflect.Slice:
+		beanPtr := reflect.New(t)
+		beanPtr.Elem().Set(reflect.MakeSlice(t, 0, 0))
+		return beanPtr
+	}
+	if !bc.opts.prototypePooling || !isPrototype(bc.getBeanType(beanName)) {
+		return reflect.New(t)
+	}
+	return reflect.ValueOf(bc.getPool(t).Get())
+}
+
+// ReleaseBean 归还原型 bean，bean 会被重置为零值后放回对象池，下次获取时重新执行属性注入
+// 只有开启原型池化并且 bean 是 ptr 原型 bean 时才会生效，归还后调用方不能再使用该 bean
+func (bc *BeanBeanFactory) ReleaseBean(beanName string, bean interface{}) {
+	if !bc.opts.prototypePooling || !isPrototype(bc.getBeanType(beanName)) {
+		return
+	}
+	beanV := reflect.ValueOf(bean)
+	if beanV.Kind() != reflect.Ptr || beanV.IsNil() {
+		return
+	}
+	t := beanV.Elem().Type()
+	bc.mu.RLock()
+	tPtr := bc.tMap[beanName]
+	bc.mu.RUnlock()
+	// 只接收该 beanName 对应类型的 bean
+	if tPtr != beanV.Type() && tPtr != t {
+		return
+	}
+	// 重置 bean，避免上一次使用的状态泄漏到下一次获取
+	beanV.Elem().Set(reflect.Zero(t))
+	bc.getPool(t).Put(bean)
+}
+
+// getPool 获取类型 t 对应的原型 bean 对象池
+func (bc *BeanBeanFactory) getPool(t reflect.Type) *sync.Pool {
+	// 先 Load，避免每次获取都分配新的对象池
+	if pool, ok := bc.poolMap.Load(t); ok {
+		return pool.(*sync.Pool)
+	}
+	pool, _ := bc.poolMap.LoadOrStore(t, &sync.Pool{
+		New: func() interface{} {
+			return reflect.New(t).Interface()
+		},
+	})
+	return pool.(*sync.Pool)
+}
+
+// createBeanWithSupplier 通过提供者创建 bean，并校验返回值能够赋值给注册的类型
+func (bc *BeanBeanFactory) createBeanWithSupplier(ctx context.Context, beanName string, supplier func() interface{}, t reflect.Type) interface{} {
+	bean := supplier()
+	if bean == nil || !reflect.TypeOf(bean).AssignableTo(t) {
+		panic(fmt.Errorf("supplier of bean %v returns %T, not implements %v", beanName, bean, t))
+	}
+	bc.invokeAfterPropertiesSet(ctx, beanName, bean)
+	bc.invokeInitMethod(beanName, reflect.ValueOf(bean))
+	return bc.initializeBean(beanName, bean, t)
+}
+
+// getBeanProcessors 获取当前所有 bean 处理器的快照，创建 bean 期间注册的处理器作用于之后创建的 bean
+// 处理器只会追加，快照范围内的元素不会被修改，因此不需要拷贝
+func (bc *BeanBeanFactory) getBeanProcessors() []BeanProcessor {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.beanProcessors
+}
+
 // resolveBeforeInstantiation 初始化 bean 前的处理
 func (bc *BeanBeanFactory) resolveBeforeInstantiation(beanName string, t reflect.Type) interface{} {
 	var bean interface{}
-	for _, bp := range bc.beanProcessors {
-		bean = bp.processBeforeInstantiation(beanName, t)
+	for i, bp := range bc.getBeanProcessors() {
+		bc.invokeProcessor(i, bp, "processBeforeInstantiation", beanName, func() {
+			bean = bp.processBeforeInstantiation(beanName, t)
+		})
 		if bean != nil {
 			return bean
 		}
@@ -278,47 +1057,53 @@ func (bc *BeanBeanFactory) resolveBeforeInstantiation(beanName string, t reflect
 	return nil
 }
 
-// populateBean 属性注入
-func (bc *BeanBeanFactory) populateBean(bean reflect.Value, t reflect.Type) {
-	for _, bp := range bc.beanProcessors {
-		bp.processPropertyValues(bean, t)
+// populateBean 属性注入，只有结构体 bean 存在 field，map 和 slice bean 跳过
+func (bc *BeanBeanFactory) populateBean(ctx context.Context, beanName string, bean reflect.Value, t reflect.Type) {
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i, bp := range bc.getBeanProcessors() {
+		bc.invokeProcessor(i, bp, "processPropertyValues", beanName, func() {
+			bp.processPropertyValues(ctx, beanName, bean, t)
+		})
 	}
 }
 
 // initializeBean 创建完 bean 后初始化 bean
+// 每个 bean 处理器接收上一个处理器返回的 bean，返回 nil 表示不替换 bean
 func (bc *BeanBeanFactory) initializeBean(beanName string, bean interface{}, t reflect.Type) interface{} {
-	wrapBean := bean
-	for _, bp := range bc.beanProcessors {
-		bean = bp.processAfterInitialization(beanName, wrapBean, t)
-		if bean != nil {
-			return bean
+	for i, bp := range bc.getBeanProcessors() {
+		var wrapBean interface{}
+		bc.invokeProcessor(i, bp, "processAfterInitialization", beanName, func() {
+			wrapBean = bp.processAfterInitialization(beanName, bean, t)
+		})
+		if wrapBean != nil {
+			bean = wrapBean
 		}
 	}
-	return bean
+	// 最后应用用户注册的装饰器
+	return bc.applyDecorators(beanName, bean)
 }
 
-// createBefore
-func (bc *BeanBeanFactory) createBefore(beanName string, beanType BeanType) {
-	// 原型 bean 直接返回
-	if isPrototype(beanType) {
-		return
-	}
-	// 判断当前 bean 是否正在创建
-	if bc.creatingMap[beanName] != nil {
-		panic(fmt.Errorf("bean %v is creating", beanName))
+// invokeProcessor 调用第 i 个 bean 处理器，用户注册的处理器 panic 时转换为指明处理器、处理阶段以及 beanName 的 error 再次 panic
+// 内置处理器的 panic 本身就是 bean 创建的错误，直接向上传递，避免嵌套创建时层层包装
+func (bc *BeanBeanFactory) invokeProcessor(i int, bp BeanProcessor, phase string, beanName string, fn func()) {
+	if i >= len(initBeanProcessors) {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(fmt.Errorf("bean processor %T %v bean %v: %w", bp, phase, beanName, toError(r)))
+			}
+		}()
 	}
-	// 标识当前 bean 正在创建
-	bc.creatingMap[beanName] = struct{}{}
+	fn()
 }
 
-// createAfter
-func (bc *BeanBeanFactory) createAfter(beanName string, beanType BeanType) {
-	// 原型 bean 直接返回
-	if isPrototype(beanType) {
-		return
+// toError 将 recover 得到的 panic 值转换为 error
+func toError(r interface{}) error {
+	if err, ok := r.(error); ok {
+		return err
 	}
-	// 将当前 bean 从正在创建 bean 列表中移除
-	bc.creatingMap[beanName] = nil
+	return fmt.Errorf("%v", r)
 }
 
 // isSingleton 判断是否是单例 bean
@@ -331,9 +1116,22 @@ func isPrototype(beanType BeanType) bool {
 	return beanType == Prototype
 }
 
+// isScope 判断是否是用户注册的自定义作用域，调用方需要持有锁
+func (bc *BeanBeanFactory) isScope(beanType BeanType) bool {
+	_, exist := bc.scMap[beanType]
+	return exist
+}
+
+// isValidBeanType 判断是否为内置作用域或者已注册的自定义作用域，调用方需要持有锁
+func (bc *BeanBeanFactory) isValidBeanType(beanType BeanType) bool {
+	return isSingleton(beanType) || isPrototype(beanType) || isGoroutine(beanType) || isThread(beanType) || isKeyed(beanType) || bc.isScope(beanType)
+}
+
 // isRegistered 判断 beanName 是否已经注册
 func (bc *BeanBeanFactory) isRegistered(beanName string) bool {
-	_, exist := bc.tMap[beanName]
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	_, exist := bc.tMap[bc.resolveAlias(beanName)]
 	return exist
 }
 
@@ -344,7 +1142,9 @@ func isBean(t reflect.Type) bool {
 	}
 	// reflect.Interface 是 reflect.TypeOf(&i).Elem().Kind() 指针传入然后调用 Elem() 返回的类型，因为 reflect 没有具体确定它的类型
 	// 这里判断有点问题，因为 var i int 传入 &i 那么这里得到的也是 Interface，无法做更加具体的区分
-	if t.Kind() == reflect.Struct || t.Kind() == reflect.Interface {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface, reflect.Map, reflect.Slice:
+		// map 和 slice 也可以作为 bean，例如 map[string]Handler、[]Middleware
 		return true
 	}
 	return false
@@ -352,20 +1152,24 @@ func isBean(t reflect.Type) bool {
 
 // getSingleton 获取单例 bean（这里以后学习 Spring 建立三级缓存解决循环依赖）
 func (bc *BeanBeanFactory) getSingleton(beanName string, allowEarlyReference bool) interface{} {
+	bc.smu.RLock()
 	// 从单例池中获取
 	bean := bc.singletonMap[beanName]
 	// 单例池不存在 bean 并且允许循环依赖
 	if bean == nil {
 		// 从早期暴露对象池中获取 bean
 		bean = bc.earlyMap[beanName]
-		if bean == nil && allowEarlyReference {
-			// 从三级缓存中获取
-			singletonFactory := bc.factoryMap[beanName]
-			if singletonFactory != nil {
-				bean = singletonFactory()
-				// 将 bean 放到早期对象池中，下次获取直接从早期对象池中获取
-				bc.earlyMap[beanName] = bean
-			}
+	}
+	singletonFactory := bc.factoryMap[beanName]
+	bc.smu.RUnlock()
+	if bean == nil && allowEarlyReference {
+		// 从三级缓存中获取，工厂方法会执行 bean 处理器，这里不能持有锁
+		if singletonFactory != nil {
+			bean = singletonFactory()
+			// 将 bean 放到早期对象池中，下次获取直接从早期对象池中获取
+			bc.smu.Lock()
+			bc.earlyMap[beanName] = bean
+			bc.smu.Unlock()
 		}
 	}
 	return bean
@@ -373,30 +1177,173 @@ func (bc *BeanBeanFactory) getSingleton(beanName string, allowEarlyReference boo
 
 // addSingleton 添加单例 bean
 func (bc *BeanBeanFactory) addSingleton(beanName string, bean interface{}) {
-	bc.earlyMap[beanName] = nil
-	bc.factoryMap[beanName] = nil
+	bc.smu.Lock()
+	defer bc.smu.Unlock()
+	// 删除而不是置为 nil，避免二级、三级缓存中残留的 key 随着 bean 的销毁、重建不断累积
+	delete(bc.earlyMap, beanName)
+	delete(bc.factoryMap, beanName)
+	// 新的实例才记录创建时间
+	if bc.singletonMap[beanName] == nil {
+		bc.createdAtMap[beanName] = time.Now()
+	}
 	bc.singletonMap[beanName] = bean
+	// 记录单例 bean 第一次创建的顺序
+	if _, exist := bc.createdMap[beanName]; !exist {
+		bc.createdMap[beanName] = struct{}{}
+		bc.creationOrder = append(bc.creationOrder, beanName)
+	}
+}
+
+// DestroyBean 销毁单例 bean，将 bean 从三级缓存中移除并调用销毁回调，下次获取时会重新创建
+// bean 的所有缓存都不再持有旧实例，旧实例可以被 GC 回收；正在创建的 bean 由创建链自己结束创建
+func (bc *BeanBeanFactory) DestroyBean(beanName string) {
+	if bean := bc.removeSingleton(beanName); bean != nil {
+		bc.destroySingleton(beanName, bean)
+	}
+}
+
+// EvictSingleton 将单例 bean 从缓存中丢弃，不调用销毁回调，下次获取时重新创建并重新注入依赖
+// 已经注入到其他 bean 中的旧实例不受影响，旧实例由 GC 回收，适用于对内存敏感、可以随时重建的 bean
+func (bc *BeanBeanFactory) EvictSingleton(beanName string) error {
+	beanType := bc.getBeanType(beanName)
+	if beanType == Invalid {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if beanType != Singleton {
+		return fmt.Errorf("bean %v is not a singleton", beanName)
+	}
+	bc.removeSingleton(beanName)
+	return nil
+}
+
+// Swap 运行时使用 newInstance 替换单例 bean，之后的 GetBean 以及提供者函数都会返回新的实例
+// golang 注入的是指针的拷贝，已经通过 field 注入了旧实例的 bean 不会感知替换，需要感知替换的 bean 应当注入提供者函数
+// 旧实例可能仍然被其他 bean 持有，因此不会调用旧实例的销毁回调，newInstance 也不会经过属性注入和初始化
+func (bc *BeanBeanFactory) Swap(beanName string, newInstance interface{}) error {
+	if bc.getBeanType(beanName) == Invalid {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if bc.getBeanType(beanName) != Singleton {
+		return fmt.Errorf("bean %v is not a singleton", beanName)
+	}
+	bc.mu.RLock()
+	t := bc.tMap[beanName]
+	bc.mu.RUnlock()
+	newT := reflect.TypeOf(newInstance)
+	if newT == nil || !newT.AssignableTo(t) {
+		return fmt.Errorf("bean %v: %T can not be assigned to %v: %w", beanName, newInstance, t, ErrTypeMismatch)
+	}
+	bc.removeSingleton(beanName)
+	bc.addSingleton(beanName, newInstance)
+	return nil
+}
+
+// removeSingleton 将 bean 从三级缓存中移除，返回移除前缓存的单例 bean
+func (bc *BeanBeanFactory) removeSingleton(beanName string) interface{} {
+	bc.smu.Lock()
+	defer bc.smu.Unlock()
+	bean := bc.singletonMap[beanName]
+	delete(bc.singletonMap, beanName)
+	delete(bc.earlyMap, beanName)
+	delete(bc.factoryMap, beanName)
+	delete(bc.createdAtMap, beanName)
+	return bean
+}
+
+// acquireCall 登记一次进行中的 GetBean 调用，已经开始 GracefulShutdown 时返回 ErrClosed
+func (bc *BeanBeanFactory) acquireCall(beanName string) error {
+	bc.cmu.Lock()
+	defer bc.cmu.Unlock()
+	if bc.closed {
+		return fmt.Errorf("bean %v: %w", beanName, ErrClosed)
+	}
+	bc.inflight++
+	return nil
+}
+
+// releaseCall 结束一次 GetBean 调用，最后一个调用结束时通知 waitInflight
+func (bc *BeanBeanFactory) releaseCall() {
+	bc.cmu.Lock()
+	defer bc.cmu.Unlock()
+	bc.inflight--
+	if bc.inflight == 0 && bc.idle != nil {
+		close(bc.idle)
+		bc.idle = nil
+	}
+}
+
+// waitInflight 拒绝新的 GetBean 调用，并等待进行中的 GetBean 调用结束，ctx 结束时返回 ctx.Err()
+func (bc *BeanBeanFactory) waitInflight(ctx context.Context) error {
+	bc.cmu.Lock()
+	bc.closed = true
+	if bc.inflight == 0 {
+		bc.cmu.Unlock()
+		return nil
+	}
+	if bc.idle == nil {
+		bc.idle = make(chan struct{})
+	}
+	idle := bc.idle
+	bc.cmu.Unlock()
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// DestroySingletons 按销毁优先级降序销毁所有单例 bean，优先级相同时按创建顺序的逆序，先创建的 bean 可能被后创建的 bean 依赖，因此后销毁
+func (bc *BeanBeanFactory) DestroySingletons() {
+	bc.smu.Lock()
+	singletonMap := bc.singletonMap
+	var beanNames []string
+	for i := len(bc.creationOrder) - 1; i >= 0; i-- {
+		if singletonMap[bc.creationOrder[i]] != nil {
+			beanNames = append(beanNames, bc.creationOrder[i])
+		}
+	}
+	bc.singletonMap = map[string]interface{}{}
+	bc.earlyMap = map[string]interface{}{}
+	bc.factoryMap = map[string]func() interface{}{}
+	bc.smu.Unlock()
+	// 按销毁优先级降序排序，优先级相同的保持创建顺序的逆序
+	priorities := make(map[string]int, len(beanNames))
+	for _, beanName := range beanNames {
+		if class := bc.getClass(beanName); class != nil {
+			priorities[beanName] = class.destroyPriority
+		}
+	}
+	sort.SliceStable(beanNames, func(i, j int) bool {
+		return priorities[beanNames[i]] > priorities[beanNames[j]]
+	})
+	for _, beanName := range beanNames {
+		bc.destroySingleton(beanName, singletonMap[beanName])
+	}
 }
 
 // addSingletonFactory
 func (bc *BeanBeanFactory) addSingletonFactory(beanName string, bean interface{}, t reflect.Type) {
+	bc.smu.Lock()
+	defer bc.smu.Unlock()
 	// 设置工厂方法，这里主要是进行 AOP 处理
 	bc.factoryMap[beanName] = func() interface{} {
-		// 注意这里是闭包的，后面修改了 bean 所以这里需要对 bean 进行一份备份
-		wrapBean := bean
-		for _, bp := range bc.beanProcessors {
-			bean = bp.processAfterInitialization(beanName, wrapBean, t)
-			if bean != nil {
-				return bean
-			}
-		}
-		return bean
+		return bc.initializeBean(beanName, bean, t)
 	}
 }
 
+// getClass 根据 beanName 获取注册的 Class 信息
+func (bc *BeanBeanFactory) getClass(beanName string) *Class {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return bc.cMap[beanName]
+}
+
 // getBeanType 根据 beanName 获取 bean 类型
 func (bc *BeanBeanFactory) getBeanType(beanName string) BeanType {
-	beanType, exist := bc.btMap[beanName]
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	beanType, exist := bc.btMap[bc.resolveAlias(beanName)]
 	if !exist {
 		return Invalid
 	}
@@ -405,46 +1352,163 @@ func (bc *BeanBeanFactory) getBeanType(beanName string) BeanType {
 
 // getBeanName 获取 field 注解的 beanName，作为 IOC 容器中唯一 bean 标识
 func getBeanName(field reflect.StructField) string {
+	autowireTag, _ := getAutowiredTag(field)
+	if autowireTag != "" && !strings.HasPrefix(autowireTag, QualifierPrefix) {
+		return autowireTag
+	}
 	return field.Tag.Get(BeanNameTag)
 }
 
 // getBeanNameWithReflectType 根据 reflect.Type 从已经注册的 bean 中获取对应的 beanName
 func (bc *BeanBeanFactory) getBeanNameWithReflectType(tape reflect.Type) string {
 	// 这里操作次数并不多，因此不需要特地维护一个 map，直接从原有 map 扫描获取即可，单纯的时间换空间
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	var beanNames []string
 	for beanName, t := range bc.tMap {
-		if t == tape {
+		// tape 是 field 的非 ptr 类型，bean 一般以 ptr 类型注册，两种都需要匹配
+		if t == tape || (t.Kind() == reflect.Ptr && t.Elem() == tape) {
+			beanNames = append(beanNames, beanName)
+		}
+	}
+	if len(beanNames) == 0 {
+		return ""
+	}
+	// 存在多个同类型 bean 时优先选择首选 bean，否则排序后取第一个，保证结果稳定
+	bc.sortBeanNames(beanNames)
+	for _, beanName := range beanNames {
+		if bc.cMap[beanName].primary {
 			return beanName
 		}
 	}
-	return ""
+	return beanNames[0]
+}
+
+// getBeanNameWithQualifier 从已经注册的 bean 中获取类型能够赋值给 tape 并且限定符为 qualifier 的 beanName
+// tape 为接口时，会匹配所有实现了该接口的 bean，再按限定符筛选
+func (bc *BeanBeanFactory) getBeanNameWithQualifier(tape reflect.Type, qualifier Qualifier) (string, error) {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	var beanNames []string
+	for beanName, t := range bc.tMap {
+		if qualifier.matches(bc.cMap[beanName].qualifier) && t.AssignableTo(tape) {
+			beanNames = append(beanNames, beanName)
+		}
+	}
+	if len(beanNames) == 0 {
+		return "", fmt.Errorf("no bean of type %v with qualifier %v", tape, qualifier)
+	}
+	if len(beanNames) > 1 {
+		return "", fmt.Errorf("more than one bean of type %v with qualifier %v: %v", tape, qualifier, beanNames)
+	}
+	return beanNames[0], nil
+}
+
+// getBeanNamesAssignableTo 从已经注册的 bean 中获取所有类型能够赋值给 tape 的 beanName，按 beanName 排序
+func (bc *BeanBeanFactory) getBeanNamesAssignableTo(tape reflect.Type) []string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	var beanNames []string
+	for beanName, t := range bc.tMap {
+		if t.AssignableTo(tape) {
+			beanNames = append(beanNames, beanName)
+		}
+	}
+	bc.sortBeanNames(beanNames)
+	return beanNames
 }
 
 // getFieldBeanName 获取字段变量的 beanName
-func getFieldBeanName(bc *BeanBeanFactory, field reflect.StructField, ft reflect.Type) string {
+// field 没有 di 注解返回 ErrNoTag；需要的 bean 没有注册返回 ErrBeanNotFound，同时返回用于自动注册的 beanName
+func getFieldBeanName(bc *BeanBeanFactory, field reflect.StructField, ft reflect.Type) (string, error) {
+	if !isAutowired(field) {
+		return "", ErrNoTag
+	}
 	// 从 Tag 中尝试获取 beanName
 	fieldBeanName := getBeanName(field)
+	// 严格模式下不允许按类型注入
+	if fieldBeanName == "" && bc.opts.strictByName {
+		return "", ErrNoBeanName
+	}
 	// 如果 field 没有对应的 beanName 注解，那么从注册的 bean 中找到相同类型的 bean 选择一个注入
 	if fieldBeanName == "" {
 		// 从已经注册的 bean 中尝试获取相同数据类型的 beanName
 		fieldBeanName = bc.getBeanNameWithReflectType(ft)
+		// 嵌入的接口 field 按接口选择唯一实现了该接口的 bean，存在多个时选择首选 bean
+		if fieldBeanName == "" && field.Anonymous && ft.Kind() == reflect.Interface {
+			candidate, err := bc.getEmbeddedCandidate(ft)
+			if err != nil {
+				return "", err
+			}
+			fieldBeanName = candidate
+		}
 		// 已注册的 bean 中不存在当前 field 类型，那么使用 ft.Name() 作为 beanName
 		if fieldBeanName == "" {
-			fieldBeanName = ft.Name()
+			return ft.Name(), ErrBeanNotFound
 		}
+		return fieldBeanName, nil
 	}
-	return fieldBeanName
+	if bc.isRegistered(fieldBeanName) {
+		return bc.canonicalName(fieldBeanName), nil
+	}
+	// 注解指定的 beanName 没有注册时，尝试按 类型 + 同名限定符 选择 bean
+	if qualifiedName, err := bc.getBeanNameWithQualifier(field.Type, Qualifier{Name: fieldBeanName}); err == nil {
+		return qualifiedName, nil
+	}
+	return fieldBeanName, ErrBeanNotFound
 }
 
-// getFieldBeanType 获取变量注入类型
-func getFieldBeanType(field reflect.StructField) BeanType {
-	autowireTag := field.Tag.Get(AutowiredTag)
-	if isSingleton(BeanType(autowireTag)) {
-		return Singleton
+// isAutowired 判断 field 是否需要注入，只要存在 di 注解就需要注入
+func isAutowired(field reflect.StructField) bool {
+	_, exist := field.Tag.Lookup(AutowiredTag)
+	return exist
+}
+
+// autowiredTagCache 缓存变量注入注解的解析结果，原型 bean 每次注入都会解析注解
+var autowiredTagCache sync.Map
+
+// getAutowiredTag 获取变量注入注解，返回注解值以及逗号分隔的选项，例如 di:"a,lazy" 返回 "a" 和 [lazy]
+// 返回的选项会被缓存共享，调用方不能修改
+func getAutowiredTag(field reflect.StructField) (string, []string) {
+	tag := field.Tag.Get(AutowiredTag)
+	if parts, ok := autowiredTagCache.Load(tag); ok {
+		return parts.([]string)[0], parts.([]string)[1:]
 	}
-	if isPrototype(BeanType(autowireTag)) {
-		return Prototype
+	parts := strings.Split(tag, ",")
+	autowiredTagCache.Store(tag, parts)
+	return parts[0], parts[1:]
+}
+
+// StructOption 变量注入注解中允许非 ptr 结构体注入的选项，例如 di:"config,struct"
+// 只对该 field 生效，没有通过 WithAllowPopulateStructBean 全局开启时，用于将值拷贝注入限制在明确需要的 field 上
+const StructOption = "struct"
+
+// OptionalOption 变量注入注解中可选注入的选项，例如 di:"cache,optional"，bean 不存在时 field 保持零值，不会自动注册
+const OptionalOption = "optional"
+
+// hasAutowiredOption 判断变量注入注解是否存在某个选项
+func hasAutowiredOption(field reflect.StructField, option string) bool {
+	_, opts := getAutowiredTag(field)
+	for _, opt := range opts {
+		if opt == option {
+			return true
+		}
+	}
+	return false
+}
+
+// getFieldBeanType 获取注入点指定的作用域，没有 scope 注解返回 Invalid，表示使用 bean 声明的作用域
+func getFieldBeanType(field reflect.StructField) BeanType {
+	return BeanType(field.Tag.Get(ScopeTag))
+}
+
+// getFieldQualifier 获取变量注入的限定符，格式为 di:"@qualifier=xxx"
+func getFieldQualifier(field reflect.StructField) string {
+	autowireTag, _ := getAutowiredTag(field)
+	if !strings.HasPrefix(autowireTag, QualifierPrefix) {
+		return ""
 	}
-	return Invalid
+	return strings.TrimPrefix(autowireTag, QualifierPrefix)
 }
 
 // isAllowEarlyReference 是否允许循环依赖
@@ -466,6 +1530,34 @@ type Options struct {
 	allowEarlyReference bool
 	// 是否允许注入非 ptr bean
 	allowPopulateStructBean bool
+	// 是否开启原型 bean 池化
+	prototypePooling bool
+	// bean 创建失败时是否直接 panic
+	failFast bool
+	// Refresh 时是否自动发现实现了 BeanProcessor 的 bean
+	autoDiscoverProcessors bool
+	// bean 创建的最大嵌套深度，小于等于 0 表示不限制
+	maxDepth int
+	// 开发模式，开启额外的装配校验
+	devMode bool
+	// 构造函数返回 error 时的重试次数
+	retryAttempts int
+	// 第一次重试前的等待时间
+	retryBackoff time.Duration
+	// 属性源，用于条件注入
+	propertySource PropertySource
+	// 容器生命周期监听器
+	lifecycleListeners []LifecyclePhaseListener
+	// 是否关闭按类型注入，di 注解必须指定 beanName
+	strictByName bool
+	// 按类型匹配到多个 bean 时是否按注册顺序排列，默认按 beanName 排列
+	registrationOrderPreserved bool
+	// 是否在注入完成后校验 validate 注解
+	validation bool
+	// 注册时没有指定 bean 类型使用的默认类型，为 Invalid 时必须指定 bean 类型
+	defaultBeanType BeanType
+	// bean 快照无法深拷贝、退化为浅拷贝时调用
+	snapshotFallback func(beanName string, err error)
 }
 
 // WithAllowEarlyReference
@@ -481,3 +1573,94 @@ func WithAllowPopulateStructBean(allowPopulateStructBean bool) Option {
 		opts.allowPopulateStructBean = allowPopulateStructBean
 	}
 }
+
+// WithPrototypePooling 开启原型 bean 池化，通过 ReleaseBean 归还的原型 bean 会被重置后复用
+func WithPrototypePooling() Option {
+	return func(opts *Options) {
+		opts.prototypePooling = true
+	}
+}
+
+// WithFailFast 设置 bean 创建失败时是否直接 panic，默认为 true
+// 为 false 时 GetBeanE 返回 error，GetBean 打印错误并返回 nil
+func WithFailFast(failFast bool) Option {
+	return func(opts *Options) {
+		opts.failFast = failFast
+	}
+}
+
+// WithAutoDiscoverProcessors 设置 Refresh 时是否自动将实现了 BeanProcessor 的 bean 注册为 bean 处理器
+func WithAutoDiscoverProcessors(autoDiscoverProcessors bool) Option {
+	return func(opts *Options) {
+		opts.autoDiscoverProcessors = autoDiscoverProcessors
+	}
+}
+
+// WithDevMode 设置是否开启开发模式，开发模式下会进行更严格的校验：
+// 注册时 di 注解指定的 beanName 必须已经注册；注入时 bean 类型必须兼容，单例 bean 不能注入原型 bean；
+// GetOrCreate 时同名 bean 的类型必须相同。生产环境应当关闭
+func WithDevMode(devMode bool) Option {
+	return func(opts *Options) {
+		opts.devMode = devMode
+	}
+}
+
+// WithCreationRetry 设置构造函数返回 error 时最多重试 attempts 次，第一次重试前等待 backoff，之后每次等待时间翻倍
+// 只对通过构造函数创建的 bean 生效，用于启动时连接网络等可能短暂失败的场景
+func WithCreationRetry(attempts int, backoff time.Duration) Option {
+	return func(opts *Options) {
+		opts.retryAttempts = attempts
+		opts.retryBackoff = backoff
+	}
+}
+
+// WithMaxDepth 设置 bean 创建的最大嵌套深度，默认为 DefaultMaxDepth，小于等于 0 表示不限制
+// 依赖链过深时以 ErrMaxDepthExceeded 结束创建，而不是栈溢出或者长时间的反射遍历
+// 嵌套深度按一次获取 bean 触发的创建链计数，并发获取 bean 互不影响
+func WithMaxDepth(maxDepth int) Option {
+	return func(opts *Options) {
+		opts.maxDepth = maxDepth
+	}
+}
+
+// WithStrictByName 关闭按类型注入，每个 di 注解都必须指定 beanName，di:"" 在 Validate 时报错、在注入时 panic
+// 用于大型项目中避免按类型注入意外注入错误的 bean；通过限定符选择 bean 以及集合注入不受影响
+func WithStrictByName() Option {
+	return func(opts *Options) {
+		opts.strictByName = true
+	}
+}
+
+// WithRegistrationOrderPreserved 按类型匹配到多个 bean 时按注册顺序排列，而不是按 beanName 排列
+// 集合注入的元素按注册顺序排列，按类型注入存在多个候选且没有首选 bean 时选择最先注册的 bean
+func WithRegistrationOrderPreserved() Option {
+	return func(opts *Options) {
+		opts.registrationOrderPreserved = true
+	}
+}
+
+// WithValidation 设置是否在注入完成后校验 validate:"required" 的 field 不能为零值，校验失败时 bean 创建失败
+func WithValidation(validation bool) Option {
+	return func(opts *Options) {
+		opts.validation = validation
+	}
+}
+
+// WithDefaultBeanType 设置默认的 bean 类型，注册时 bean 类型为 Invalid 的 bean 使用该类型，例如：
+//
+//	ioc := NewIOC(WithDefaultBeanType(Singleton))
+//	ioc.Register(NewClass("a", (*A)(nil), Invalid))
+//
+// 没有设置时 bean 类型必须明确指定，否则注册返回错误
+func WithDefaultBeanType(beanType BeanType) Option {
+	return func(opts *Options) {
+		opts.defaultBeanType = beanType
+	}
+}
+
+// WithPropertySource 设置属性源，diif 条件注入注解从属性源中读取属性
+func WithPropertySource(propertySource PropertySource) Option {
+	return func(opts *Options) {
+		opts.propertySource = propertySource
+	}
+}
@@ -0,0 +1,731 @@
+package gioc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// slowInit 初始化阻塞的 bean
+type slowInit struct {
+	calls *int32
+}
+
+func (s *slowInit) AfterPropertiesSet(ctx context.Context) error {
+	atomic.AddInt32(s.calls, 1)
+	time.Sleep(100 * time.Millisecond)
+	return nil
+}
+
+func TestGetBeanWithTimeout(t *testing.T) {
+	var calls int32
+	ioc := NewIOC(WithFailFast(false))
+	class := NewClass("slow", (*slowInit)(nil), Singleton)
+	class.SetSupplier(func() interface{} { return &slowInit{calls: &calls} })
+	if err := ioc.Register(class); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanWithTimeout("slow", 10*time.Millisecond); !errors.Is(err, ErrTimeout) {
+		t.Fatalf("got %v, want ErrTimeout", err)
+	}
+	// 超时后重试等待后台创建完成，不会报循环依赖，也不会重复创建
+	bean, err := ioc.GetBeanWithTimeout("slow", time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again := ioc.GetBean("slow"); again != bean {
+		t.Fatalf("got %p, want cached %p", again, bean)
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Fatalf("AfterPropertiesSet called %d times, want 1", n)
+	}
+}
+
+// missingService 没有任何实现注册的接口
+type missingService interface {
+	Serve()
+}
+
+// brokenBean 依赖一个没有注册的接口 bean，接口 bean 无法自动注册，创建必然失败
+type brokenBean struct {
+	Missing missingService `di:"missing"`
+}
+
+func TestGetBeanWithFallbackNotRegistered(t *testing.T) {
+	ioc := NewIOC()
+	fallback := &slowInit{}
+	if bean := ioc.GetBeanWithFallback("absent", func() interface{} { return fallback }); bean != fallback {
+		t.Fatalf("got %v, want fallback", bean)
+	}
+}
+
+func TestGetBeanWithFallbackPropagatesCreationError(t *testing.T) {
+	for _, failFast := range []bool{true, false} {
+		ioc := NewIOC(WithFailFast(failFast))
+		if err := ioc.Register(NewClass("broken", (*brokenBean)(nil), Singleton)); err != nil {
+			t.Fatal(err)
+		}
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Fatalf("failFast=%v: creation error was not propagated", failFast)
+				}
+			}()
+			ioc.GetBeanWithFallback("broken", func() interface{} {
+				t.Fatalf("failFast=%v: fallback used for a registered bean", failFast)
+				return nil
+			})
+		}()
+	}
+}
+
+type cycleSelf struct {
+	Self *cycleSelf `di:"cycleSelf"`
+	x    int
+}
+
+type pairA struct {
+	B *pairB `di:"pairB"`
+	x int
+}
+
+type pairB struct {
+	A *pairA `di:"pairA"`
+	x int
+}
+
+type ringA struct {
+	B *ringB `di:"ringB"`
+	x int
+}
+
+type ringB struct {
+	C *ringC `di:"ringC"`
+	x int
+}
+
+type ringC struct {
+	A *ringA `di:"ringA"`
+	x int
+}
+
+// newCountingIOC 创建允许早期对象的容器，注册 classes 并统计每个 bean 实例化的次数
+func newCountingIOC(t *testing.T, classes ...*Class) (*IOC, map[string]int) {
+	ioc := NewIOC(WithAllowEarlyReference(true))
+	counts := map[string]int{}
+	err := ioc.RegisterBeanProcessorFunc(PhasePropertyValues, func(beanName string, bean interface{}) interface{} {
+		counts[beanName]++
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioc.MustRegisterAll(classes...)
+	return ioc, counts
+}
+
+func assertCreatedOnce(t *testing.T, counts map[string]int, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		if n := counts[name]; n != 1 {
+			t.Fatalf("bean %v created %d times, want 1", name, n)
+		}
+	}
+}
+
+func TestCircularDependencySelf(t *testing.T) {
+	ioc, counts := newCountingIOC(t, NewClass("cycleSelf", (*cycleSelf)(nil), Singleton))
+	a := ioc.GetBean("cycleSelf").(*cycleSelf)
+	if a.Self != a {
+		t.Fatalf("Self = %p, want %p", a.Self, a)
+	}
+	assertCreatedOnce(t, counts, "cycleSelf")
+}
+
+func TestCircularDependencyTwoBeans(t *testing.T) {
+	ioc, counts := newCountingIOC(t,
+		NewClass("pairA", (*pairA)(nil), Singleton),
+		NewClass("pairB", (*pairB)(nil), Singleton),
+	)
+	a := ioc.GetBean("pairA").(*pairA)
+	b := ioc.GetBean("pairB").(*pairB)
+	if a.B != b || b.A != a {
+		t.Fatalf("a.B = %p, b.A = %p, want %p and %p", a.B, b.A, b, a)
+	}
+	assertCreatedOnce(t, counts, "pairA", "pairB")
+}
+
+func TestCircularDependencyThreeBeans(t *testing.T) {
+	// 从环上的每一个 bean 开始获取，结果都一样
+	for _, first := range []string{"ringA", "ringB", "ringC"} {
+		ioc, counts := newCountingIOC(t,
+			NewClass("ringA", (*ringA)(nil), Singleton),
+			NewClass("ringB", (*ringB)(nil), Singleton),
+			NewClass("ringC", (*ringC)(nil), Singleton),
+		)
+		ioc.GetBean(first)
+		a := ioc.GetBean("ringA").(*ringA)
+		b := ioc.GetBean("ringB").(*ringB)
+		c := ioc.GetBean("ringC").(*ringC)
+		if a.B != b || b.C != c || c.A != a {
+			t.Fatalf("first %v: a.B = %p, b.C = %p, c.A = %p, want %p, %p, %p", first, a.B, b.C, c.A, b, c, a)
+		}
+		assertCreatedOnce(t, counts, "ringA", "ringB", "ringC")
+	}
+}
+
+func TestCircularDependencyWithoutEarlyReference(t *testing.T) {
+	// 不允许早期对象时无法解决的循环依赖在注册时就会被拒绝
+	ioc := NewIOC(WithAllowEarlyReference(false))
+	if err := ioc.Register(NewClass("pairA", (*pairA)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("pairB", (*pairB)(nil), Singleton)); !errors.Is(err, ErrWouldCreateCircularDependency) {
+		t.Fatalf("got %v, want ErrWouldCreateCircularDependency", err)
+	}
+}
+
+type pooledDep struct {
+	x int
+}
+
+type pooledBean struct {
+	Dep   *pooledDep `di:"pooledDep"`
+	State string
+	Data  []byte
+}
+
+func newPoolingIOC(opts ...Option) *IOC {
+	ioc := NewIOC(opts...)
+	ioc.MustRegisterAll(
+		NewClass("pooledDep", (*pooledDep)(nil), Prototype),
+		NewClass("pooledBean", (*pooledBean)(nil), Prototype),
+	)
+	return ioc
+}
+
+func TestPrototypePoolingResetsReleasedBean(t *testing.T) {
+	ioc := newPoolingIOC(WithPrototypePooling())
+	for i := 0; i < 10; i++ {
+		bean := ioc.GetBean("pooledBean").(*pooledBean)
+		if bean.State != "" || bean.Data != nil {
+			t.Fatalf("acquisition %d: state leaked from previous use: %+v", i, bean)
+		}
+		// 每次获取都重新注入，原型依赖也是新的实例
+		if bean.Dep == nil {
+			t.Fatalf("acquisition %d: dependency not injected", i)
+		}
+		bean.State = "dirty"
+		bean.Data = []byte("dirty")
+		bean.Dep.x = i + 1
+		ioc.ReleaseBean("pooledBean", bean)
+	}
+}
+
+func BenchmarkPrototypePooling(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option
+	}{
+		{"without", nil},
+		{"with", []Option{WithPrototypePooling()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ioc := newPoolingIOC(bc.opts...)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				bean := ioc.GetBean("pooledBean")
+				ioc.ReleaseBean("pooledBean", bean)
+			}
+		})
+	}
+}
+
+// gcPayload 只被 bean 持有的对象，bean 被回收后 gcPayload 才会被回收
+// 终结器设置在 gcPayload 上而不是 bean 上，因为存在循环引用的 bean 不保证会执行终结器
+// 大小超过 16 字节，避免通过 tiny allocator 分配，与其他对象共用内存块时不保证会执行终结器
+type gcPayload struct {
+	buf [64]byte
+}
+
+// gcBean 没有依赖的 bean
+type gcBean struct {
+	payload *gcPayload
+}
+
+func (b *gcBean) setPayload(payload *gcPayload) { b.payload = payload }
+
+type gcCycleA struct {
+	B       *gcCycleB `di:"gcCycleB"`
+	payload *gcPayload
+}
+
+type gcCycleB struct {
+	A *gcCycleA `di:"gcCycleA"`
+	x int
+}
+
+func (a *gcCycleA) setPayload(payload *gcPayload) { a.payload = payload }
+
+// attachPayload 为 bean 挂上 gcPayload，payload 被回收时关闭 collected
+func attachPayload(ioc *IOC, beanName string, collected chan struct{}) {
+	payload := &gcPayload{}
+	runtime.SetFinalizer(payload, func(*gcPayload) { close(collected) })
+	ioc.GetBean(beanName).(interface{ setPayload(*gcPayload) }).setPayload(payload)
+}
+
+func waitCollected(t *testing.T, collected chan struct{}) {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		runtime.GC()
+		select {
+		case <-collected:
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatalf("%v: destroyed bean was not garbage collected", t.Name())
+}
+
+func TestDestroyBeanReleasesInstance(t *testing.T) {
+	for _, allowEarlyReference := range []bool{false, true} {
+		ioc := NewIOC(WithAllowEarlyReference(allowEarlyReference))
+		if err := ioc.Register(NewClass("gcBean", (*gcBean)(nil), Singleton)); err != nil {
+			t.Fatal(err)
+		}
+		collected := make(chan struct{})
+		attachPayload(ioc, "gcBean", collected)
+		ioc.DestroyBean("gcBean")
+		waitCollected(t, collected)
+		// 容器本身必须保持可达，否则旧实例会随容器一起被回收
+		runtime.KeepAlive(ioc)
+	}
+}
+
+func TestDestroyBeanReleasesEarlyExposedInstance(t *testing.T) {
+	// gcCycleA 与 gcCycleB 构成循环依赖，gcCycleA 的早期对象经过 factoryMap 和 earlyMap 注入到 gcCycleB 中
+	ioc, _ := newCountingIOC(t,
+		NewClass("gcCycleA", (*gcCycleA)(nil), Singleton),
+		NewClass("gcCycleB", (*gcCycleB)(nil), Singleton),
+	)
+	collected := make(chan struct{})
+	attachPayload(ioc, "gcCycleA", collected)
+	ioc.DestroyBean("gcCycleA")
+	ioc.DestroyBean("gcCycleB")
+	waitCollected(t, collected)
+	runtime.KeepAlive(ioc)
+}
+
+func TestFailFastPanicsOnMissingDependency(t *testing.T) {
+	ioc := NewIOC(WithFailFast(true))
+	if err := ioc.Register(NewClass("broken", (*brokenBean)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("want panic for missing dependency")
+		}
+	}()
+	ioc.GetBeanE("broken")
+}
+
+func TestFailFastDisabledReturnsError(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	if err := ioc.Register(NewClass("broken", (*brokenBean)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("broken"); err == nil || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("got %v, want error naming the missing dependency", err)
+	}
+	if bean := ioc.GetBean("broken"); bean != nil {
+		t.Fatalf("got %v, want nil", bean)
+	}
+	// 失败的创建不会留下半成品单例
+	if n := ioc.GetCreatedSingletonCount(); n != 0 {
+		t.Fatalf("created singletons = %d, want 0", n)
+	}
+}
+
+type cacheHolder struct {
+	C Cache `di:""`
+}
+
+func TestRegisterInterfaceBean(t *testing.T) {
+	ioc := NewIOC()
+	impl := &redisCache{}
+	if err := ioc.RegisterInterfaceBean("cache", reflect.TypeOf((*Cache)(nil)).Elem(), func() interface{} { return impl }); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("cacheHolder", (*cacheHolder)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if holder := ioc.GetBean("cacheHolder").(*cacheHolder); holder.C != impl {
+		t.Fatalf("got %v, want the supplied implementation", holder.C)
+	}
+}
+
+func TestRegisterInterfaceBeanNotImplemented(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	if err := ioc.RegisterInterfaceBean("cache", reflect.TypeOf((*Cache)(nil)).Elem(), func() interface{} { return &pairA{} }); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("cache"); err == nil || !strings.Contains(err.Error(), "not implements") {
+		t.Fatalf("got %v, want error for a supplier not implementing the interface", err)
+	}
+}
+
+type scopedDep struct {
+	x int
+}
+
+type scopeOverride struct {
+	// 单例 bean 在注入点作为原型获取
+	Fresh  *scopedDep `di:"single" scope:"p"`
+	Shared *scopedDep `di:"single"`
+	// 原型 bean 在注入点作为单例获取
+	Proto1 *scopedDep `di:"proto" scope:"s"`
+	Proto2 *scopedDep `di:"proto" scope:"s"`
+}
+
+func TestFieldScopeOverride(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("single", (*scopedDep)(nil), Singleton),
+		NewClass("proto", (*scopedDep)(nil), Prototype),
+		NewClass("override1", (*scopeOverride)(nil), Prototype),
+	)
+	o1 := ioc.GetBean("override1").(*scopeOverride)
+	o2 := ioc.GetBean("override1").(*scopeOverride)
+	single := ioc.GetBean("single")
+	if o1.Shared != single || o2.Shared != single {
+		t.Fatal("field without scope tag did not get the declared singleton")
+	}
+	if o1.Fresh == single || o2.Fresh == single || o1.Fresh == o2.Fresh {
+		t.Fatal("scope:\"p\" did not create a fresh instance of a singleton")
+	}
+	if o1.Proto1 != o1.Proto2 || o1.Proto1 != o2.Proto1 {
+		t.Fatal("scope:\"s\" did not share one instance of a prototype")
+	}
+	if ioc.GetBean("proto") == o1.Proto1 {
+		t.Fatal("prototype declared bean got by name returned the shared instance")
+	}
+}
+
+type namedS struct {
+	x int
+}
+
+type namedSUser struct {
+	S *namedS `di:"s"`
+	P *namedS `di:"p" scope:"p"`
+}
+
+func TestBeanNamedLikeScope(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("s", (*namedS)(nil), Singleton),
+		NewClass("p", (*namedS)(nil), Singleton),
+		NewClass("namedSUser", (*namedSUser)(nil), Singleton),
+	)
+	user := ioc.GetBean("namedSUser").(*namedSUser)
+	// di 注解只表示 beanName，名为 s 的 bean 按名称注入
+	if user.S == nil || user.S != ioc.GetBean("s") {
+		t.Fatalf("got %p, want bean named s", user.S)
+	}
+	// 作用域由 scope 注解单独指定，名为 p 的单例 bean 在这里作为原型获取
+	if user.P == nil || user.P == ioc.GetBean("p") {
+		t.Fatalf("got %p, want a fresh instance of bean named p", user.P)
+	}
+}
+
+// legacyTagUser 旧版本的 di:"s" beanName:"b" 写法
+type legacyTagUser struct {
+	S *namedS `di:"s" beanName:"namedS"`
+}
+
+func TestLegacyBeanNameTagRejected(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	ioc.MustRegisterAll(
+		NewClass("namedS", (*namedS)(nil), Singleton),
+		NewClass("legacyTagUser", (*legacyTagUser)(nil), Singleton),
+	)
+	migration := "use `di:\"namedS\" scope:\"s\"` instead"
+	if err := ioc.Validate(); err == nil || !strings.Contains(err.Error(), migration) {
+		t.Fatalf("Validate() = %v, want a migration hint", err)
+	}
+	if _, err := ioc.GetBeanE("legacyTagUser"); err == nil || !strings.Contains(err.Error(), migration) {
+		t.Fatalf("GetBeanE() = %v, want a migration hint", err)
+	}
+}
+
+type evictable struct {
+	Fresh  *scopedDep `di:"evictProto"`
+	Shared *scopedDep `di:"evictSingle"`
+}
+
+func TestEvictSingleton(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("evictProto", (*scopedDep)(nil), Prototype),
+		NewClass("evictSingle", (*scopedDep)(nil), Singleton),
+		NewClass("evictable", (*evictable)(nil), Singleton),
+	)
+	old := ioc.GetBean("evictable").(*evictable)
+	if err := ioc.EvictSingleton("evictable"); err != nil {
+		t.Fatal(err)
+	}
+	recreated := ioc.GetBean("evictable").(*evictable)
+	if recreated == old {
+		t.Fatal("evicted singleton was not recreated")
+	}
+	// 重新创建时重新注入，原型依赖是新的实例，单例依赖仍然是同一个
+	if recreated.Fresh == nil || recreated.Fresh == old.Fresh {
+		t.Fatal("prototype dependency was not re-injected")
+	}
+	if recreated.Shared != old.Shared || recreated.Shared != ioc.GetBean("evictSingle") {
+		t.Fatal("singleton dependency changed after eviction")
+	}
+	if ioc.GetBean("evictable") != recreated {
+		t.Fatal("recreated singleton was not cached")
+	}
+	if err := ioc.EvictSingleton("evictProto"); err == nil {
+		t.Fatal("want error evicting a prototype bean")
+	}
+	if err := ioc.EvictSingleton("absent"); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+}
+
+type swapConfig struct {
+	Version int
+}
+
+type swapConsumer struct {
+	Config func() (*swapConfig, error) `di:"swapConfig"`
+	Direct *swapConfig                 `di:"swapConfig"`
+}
+
+func TestSwap(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("swapConfig", (*swapConfig)(nil), Singleton),
+		NewClass("swapConsumer", (*swapConsumer)(nil), Singleton),
+	)
+	consumer := ioc.GetBean("swapConsumer").(*swapConsumer)
+	old := consumer.Direct
+	next := &swapConfig{Version: 2}
+	if err := ioc.Swap("swapConfig", next); err != nil {
+		t.Fatal(err)
+	}
+	if config, err := consumer.Config(); err != nil || config != next {
+		t.Fatalf("provider got %v, %v, want the swapped instance", config, err)
+	}
+	if ioc.GetBean("swapConfig") != next {
+		t.Fatal("GetBean did not return the swapped instance")
+	}
+	// field 注入的是指针的拷贝，不会感知替换
+	if consumer.Direct != old {
+		t.Fatal("directly injected field changed after swap")
+	}
+	if err := ioc.Swap("swapConfig", &pairA{}); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("got %v, want ErrTypeMismatch", err)
+	}
+}
+
+var errProcessorBroken = errors.New("processor broken")
+
+// panickingProcessor 初始化后处理时 panic 的 bean 处理器
+type panickingProcessor struct {
+	recordingProcessor
+}
+
+func (p *panickingProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
+	panic(errProcessorBroken)
+}
+
+func TestBeanProcessorPanicBecomesError(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	if err := ioc.RegisterBeanProcessor(NewClass("panicker", (*panickingProcessor)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("processed", (*processed)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	_, err := ioc.GetBeanE("processed")
+	if !errors.Is(err, errProcessorBroken) {
+		t.Fatalf("got %v, want wrapped errProcessorBroken", err)
+	}
+	for _, want := range []string{"panickingProcessor", "processed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not name %v", err, want)
+		}
+	}
+}
+
+// routeTable map bean，通过初始化方法填充
+type routeTable map[string]string
+
+func (r routeTable) PostConstruct() {
+	r["/"] = "index"
+}
+
+// middlewares slice bean
+type middlewares []string
+
+type router struct {
+	Routes      routeTable  `di:"routes"`
+	Middlewares middlewares `di:"middlewares"`
+}
+
+func TestMapAndSliceBeans(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("routes", (*routeTable)(nil), Singleton).SetInitMethod("PostConstruct"),
+		NewClass("middlewares", (*middlewares)(nil), Singleton),
+		NewClass("router", (*router)(nil), Singleton),
+		NewClass("labels", (*map[string]string)(nil), Singleton),
+	)
+	if err := ioc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	routes := *ioc.GetBean("routes").(*routeTable)
+	if routes["/"] != "index" {
+		t.Fatalf("routes = %v, want populated by PostConstruct", routes)
+	}
+	if labels := *ioc.GetBean("labels").(*map[string]string); labels == nil {
+		t.Fatal("map[string]string bean should be an empty non-nil map")
+	}
+	if mws := *ioc.GetBean("middlewares").(*middlewares); mws == nil || len(mws) != 0 {
+		t.Fatalf("middlewares = %#v, want empty non-nil slice", mws)
+	}
+	r := ioc.GetBean("router").(*router)
+	if r.Routes["/"] != "index" || r.Middlewares == nil {
+		t.Fatalf("router = %+v", r)
+	}
+	// map 是引用类型，注入的是共享的 map bean
+	routes["/health"] = "ok"
+	if r.Routes["/health"] != "ok" {
+		t.Fatal("injected map should share the map bean")
+	}
+}
+
+type strictDep struct {
+	x int
+}
+
+type strictByType struct {
+	Dep *strictDep `di:""`
+}
+
+type strictByName struct {
+	Dep *strictDep `di:"strictDep"`
+}
+
+func newStrictIOC(user *Class, opts ...Option) *IOC {
+	ioc := NewIOC(opts...)
+	ioc.MustRegisterAll(NewClass("strictDep", (*strictDep)(nil), Singleton), user)
+	return ioc
+}
+
+func TestStrictByName(t *testing.T) {
+	byType := NewClass("user", (*strictByType)(nil), Singleton)
+	if err := newStrictIOC(byType).Validate(); err != nil {
+		t.Fatalf("by-type injection should validate by default: %v", err)
+	}
+	ioc := newStrictIOC(byType, WithStrictByName(), WithFailFast(false))
+	var report *ValidationReport
+	if err := ioc.Validate(); !errors.As(err, &report) || len(report.Errors) != 1 ||
+		report.Errors[0].BeanName != "user" || !strings.Contains(report.Errors[0].Message, ErrNoBeanName.Error()) {
+		t.Fatalf("Validate() = %v, want a single ErrNoBeanName error for bean user", err)
+	}
+	if _, err := ioc.GetBeanE("user"); !errors.Is(err, ErrNoBeanName) {
+		t.Fatalf("GetBeanE() = %v, want ErrNoBeanName", err)
+	}
+	ioc = newStrictIOC(NewClass("user", (*strictByName)(nil), Singleton), WithStrictByName())
+	if err := ioc.Validate(); err != nil {
+		t.Fatalf("named injection should validate in strict mode: %v", err)
+	}
+	if ioc.GetBean("user").(*strictByName).Dep == nil {
+		t.Fatal("named dependency not injected in strict mode")
+	}
+}
+
+func TestLookupBean(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	ioc.MustRegisterAll(
+		NewClass("counted", (*counted)(nil), Singleton),
+		NewClass("nilBean", (*counted)(nil), Singleton).SetSupplier(func() interface{} {
+			return nil
+		}),
+		NewClass("broken", (*slowInit)(nil), Singleton).SetConstructor(func() (*slowInit, error) {
+			return nil, errors.New("boom")
+		}),
+	)
+	if bean, ok := ioc.LookupBean("counted"); !ok || bean != ioc.GetBean("counted") {
+		t.Fatalf("registered bean: got %v, %v", bean, ok)
+	}
+	if bean, ok := ioc.LookupBean("missing"); ok || bean != nil {
+		t.Fatalf("unregistered bean: got %v, %v", bean, ok)
+	}
+	// 已注册但是得到 nil，与没有注册区分开
+	for _, beanName := range []string{"nilBean", "broken"} {
+		if bean, ok := ioc.LookupBean(beanName); !ok || !isNilBean(bean) {
+			t.Fatalf("%v: got %v, %v, want nil, true", beanName, bean, ok)
+		}
+	}
+}
+
+func TestWithDefaultBeanType(t *testing.T) {
+	ioc := NewIOC(WithDefaultBeanType(Prototype))
+	if err := ioc.Register(NewClass("counted", (*counted)(nil), Invalid)); err != nil {
+		t.Fatal(err)
+	}
+	infos := ioc.ListBeans(BeanFilter{})
+	if len(infos) != 1 || infos[0].Scope != Prototype {
+		t.Fatalf("ListBeans() = %+v, want one prototype bean", infos)
+	}
+	if ioc.GetBean("counted") == ioc.GetBean("counted") {
+		t.Fatal("bean registered without a type should use the default prototype scope")
+	}
+}
+
+func TestWithoutDefaultBeanType(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("counted", (*counted)(nil), Invalid)); err == nil {
+		t.Fatal("expected error registering a bean without a type")
+	}
+}
+
+type handlerServer struct {
+	Handlers    map[string]Handler `di:"handlerMap"`
+	Middlewares []Handler          `di:"middlewareChain"`
+}
+
+func TestInterfaceMapAndSliceBeansByName(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("handlerA", (*handlerA)(nil), Singleton),
+		NewClass("handlerMap", (*map[string]Handler)(nil), Singleton),
+		NewClass("middlewareChain", (*[]Handler)(nil), Singleton),
+		NewClass("handlerServer", (*handlerServer)(nil), Singleton),
+	)
+	if err := ioc.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	handlers := *ioc.GetBean("handlerMap").(*map[string]Handler)
+	handlers["/a"] = ioc.GetBean("handlerA").(Handler)
+	server := ioc.GetBean("handlerServer").(*handlerServer)
+	// 注入的是注册的 map bean，而不是收集所有 Handler bean
+	if len(server.Handlers) != 1 || server.Handlers["/a"] == nil {
+		t.Fatalf("Handlers = %v, want the registered handlerMap bean", server.Handlers)
+	}
+	if server.Middlewares == nil || len(server.Middlewares) != 0 {
+		t.Fatalf("Middlewares = %#v, want the empty middlewareChain bean", server.Middlewares)
+	}
+}
@@ -0,0 +1,355 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BeanFilter bean 过滤条件，字段为零值表示不限制
+type BeanFilter struct {
+	// beanName
+	Name string
+	// bean 类型能够赋值给 Type
+	Type reflect.Type
+	// bean 作用域
+	Scope BeanType
+	// 只返回已经创建的单例 bean
+	Created bool
+}
+
+// BeanInfo 已注册 bean 的信息
+type BeanInfo struct {
+	Name    string
+	Scope   BeanType
+	Type    reflect.Type
+	Created bool
+	// 注册顺序
+	Order int
+}
+
+// ListBeans 按条件列出已注册的 bean 信息，结果按 beanName 排序
+func (bc *BeanBeanFactory) ListBeans(filter BeanFilter) []BeanInfo {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	infos := []BeanInfo{}
+	for beanName, beanType := range bc.btMap {
+		if filter.Name != "" && filter.Name != beanName {
+			continue
+		}
+		if filter.Scope != Invalid && filter.Scope != beanType {
+			continue
+		}
+		t := bc.tMap[beanName]
+		if filter.Type != nil && !t.AssignableTo(filter.Type) {
+			continue
+		}
+		// 只有单例 bean 会被缓存，因此只有单例 bean 存在已创建的状态
+		created := isSingleton(beanType) && bc.isSingletonCreated(beanName)
+		if filter.Created && !created {
+			continue
+		}
+		infos = append(infos, BeanInfo{
+			Name:    beanName,
+			Scope:   beanType,
+			Type:    t,
+			Created: created,
+			Order:   bc.cMap[beanName].order,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// FindBeanDefinitionByType 获取注册类型能够赋值给 t 的所有 beanName，t 为接口时返回所有实现了该接口的 bean，结果按 beanName 排序
+// t 为非 ptr 结构体时，以该结构体 ptr 类型注册的 bean 也会匹配，与按类型注入的规则一致
+func (bc *BeanBeanFactory) FindBeanDefinitionByType(t reflect.Type) []string {
+	if t == nil {
+		return nil
+	}
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	var beanNames []string
+	for beanName, bt := range bc.tMap {
+		if bt.AssignableTo(t) || (t.Kind() != reflect.Interface && bt.Kind() == reflect.Ptr && bt.Elem() == t) {
+			beanNames = append(beanNames, beanName)
+		}
+	}
+	sort.Strings(beanNames)
+	return beanNames
+}
+
+// GetBeansWhere 获取所有满足 pred 的 bean，key 为 beanName，attrs 为 bean 限定符的属性
+// 满足条件的 bean 会按各自的作用域获取，没有创建的单例 bean 会被创建；协程作用域的 bean 需要令牌，不会返回
+func (bc *BeanBeanFactory) GetBeansWhere(pred func(name string, attrs map[string]string, t reflect.Type) bool) map[string]interface{} {
+	type candidate struct {
+		name  string
+		attrs map[string]string
+		t     reflect.Type
+	}
+	bc.mu.RLock()
+	candidates := make([]candidate, 0, len(bc.cMap))
+	for beanName, class := range bc.cMap {
+		if isGoroutine(bc.btMap[beanName]) || isKeyed(bc.btMap[beanName]) {
+			continue
+		}
+		// 复制一份，避免 pred 修改注册信息
+		candidates = append(candidates, candidate{name: beanName, attrs: class.qualifier.copy().Attributes, t: bc.tMap[beanName]})
+	}
+	bc.mu.RUnlock()
+	beans := map[string]interface{}{}
+	for _, c := range candidates {
+		if c.attrs == nil {
+			c.attrs = map[string]string{}
+		}
+		if !pred(c.name, c.attrs, c.t) {
+			continue
+		}
+		if bean := bc.GetBean(c.name); bean != nil {
+			beans[c.name] = bean
+		}
+	}
+	return beans
+}
+
+// InjectedDependencies 获取 bean 最近一次创建时实际注入的依赖，key 为 field 名称，value 为注入的 beanName
+// 延迟注入的 field 不会被记录，bean 未创建过返回空 map
+func (bc *BeanBeanFactory) InjectedDependencies(beanName string) map[string]string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	deps := map[string]string{}
+	for fieldName, fieldBeanName := range bc.injectedMap[beanName] {
+		deps[fieldName] = fieldBeanName
+	}
+	return deps
+}
+
+// setInjectedDependencies 记录 bean 实际注入的依赖
+func (bc *BeanBeanFactory) setInjectedDependencies(beanName string, deps map[string]string) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.injectedMap[beanName] = deps
+}
+
+// isSingletonCreated 判断单例 bean 是否已经创建
+func (bc *BeanBeanFactory) isSingletonCreated(beanName string) bool {
+	bc.smu.RLock()
+	defer bc.smu.RUnlock()
+	return bc.singletonMap[beanName] != nil
+}
+
+// GetAllBeanTypes 获取所有已注册 bean 的注册类型，返回的是副本，修改不会影响容器
+// 类型与注册时传入的一致，以 (*A)(nil) 注册的是 *A，以 A{} 注册的是 A，使用时需要注意区分 ptr 与非 ptr
+func (bc *BeanBeanFactory) GetAllBeanTypes() map[string]reflect.Type {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	types := make(map[string]reflect.Type, len(bc.tMap))
+	for beanName, t := range bc.tMap {
+		types[beanName] = t
+	}
+	return types
+}
+
+// GetRegisteredBeanCount 获取已注册的 bean 数量
+func (bc *BeanBeanFactory) GetRegisteredBeanCount() int {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	return len(bc.btMap)
+}
+
+// countGet 记录一次 bean 的获取，没有注册的 bean 不记录
+func (bc *BeanBeanFactory) countGet(beanName string) {
+	bc.mu.RLock()
+	count := bc.getCountMap[bc.resolveAlias(beanName)]
+	bc.mu.RUnlock()
+	if count != nil {
+		atomic.AddInt64(count, 1)
+	}
+}
+
+// GetBeanHistogram 获取每个已注册 bean 通过 GetBean、GetBeanE 获取的次数，从未获取过的 bean 为 0
+// Refresh 等容器内部的获取以及注入不计入，返回的是副本
+func (bc *BeanBeanFactory) GetBeanHistogram() map[string]int {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	histogram := make(map[string]int, len(bc.getCountMap))
+	for beanName, count := range bc.getCountMap {
+		histogram[beanName] = int(atomic.LoadInt64(count))
+	}
+	return histogram
+}
+
+// BeanHolder bean 实例以及 bean 的元信息
+type BeanHolder struct {
+	Instance interface{}
+	Name     string
+	Type     reflect.Type
+	Scope    BeanType
+	// 单例 bean 为当前实例添加到单例池的时间，其他作用域为本次获取的时间
+	CreatedAt time.Time
+}
+
+// GetBeanHolder 获取 bean 实例以及 bean 的元信息，获取 bean 失败时返回 error
+func (bc *BeanBeanFactory) GetBeanHolder(beanName string) (*BeanHolder, error) {
+	bean, err := bc.GetBeanE(beanName)
+	if err != nil {
+		return nil, err
+	}
+	if bean == nil {
+		return nil, fmt.Errorf("bean %v is nil", beanName)
+	}
+	beanName = bc.canonicalName(beanName)
+	holder := &BeanHolder{
+		Instance: bean,
+		Name:     beanName,
+		Scope:    bc.getBeanType(beanName),
+	}
+	bc.mu.RLock()
+	holder.Type = bc.tMap[beanName]
+	bc.mu.RUnlock()
+	if isSingleton(holder.Scope) {
+		bc.smu.RLock()
+		holder.CreatedAt = bc.createdAtMap[beanName]
+		bc.smu.RUnlock()
+	} else {
+		holder.CreatedAt = time.Now()
+	}
+	return holder, nil
+}
+
+// GetCreatedSingletonCount 获取已经创建的单例 bean 数量
+func (bc *BeanBeanFactory) GetCreatedSingletonCount() int {
+	bc.smu.RLock()
+	defer bc.smu.RUnlock()
+	count := 0
+	for _, bean := range bc.singletonMap {
+		if bean != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// GetCreationOrder 获取单例 bean 第一次添加到单例池的顺序，可以用于排查非预期的提前初始化
+func (bc *BeanBeanFactory) GetCreationOrder() []string {
+	bc.smu.RLock()
+	defer bc.smu.RUnlock()
+	return append([]string{}, bc.creationOrder...)
+}
+
+// RegistrationOrder 按注册顺序获取已注册的 beanName，Rebind 的 bean 保持原来的位置
+func (bc *BeanBeanFactory) RegistrationOrder() []string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	beanNames := make([]string, 0, len(bc.cMap))
+	for beanName := range bc.cMap {
+		beanNames = append(beanNames, beanName)
+	}
+	sort.Slice(beanNames, func(i, j int) bool {
+		return bc.cMap[beanNames[i]].order < bc.cMap[beanNames[j]].order
+	})
+	return beanNames
+}
+
+// sortBeanNames 对按类型匹配到的 beanName 排序，默认按 beanName 排序，开启 WithRegistrationOrderPreserved 时按注册顺序排序
+// 调用方需要持有锁
+func (bc *BeanBeanFactory) sortBeanNames(beanNames []string) {
+	if !bc.opts.registrationOrderPreserved {
+		sort.Strings(beanNames)
+		return
+	}
+	sort.Slice(beanNames, func(i, j int) bool {
+		return bc.cMap[beanNames[i]].order < bc.cMap[beanNames[j]].order
+	})
+}
+
+// StartupDuration 获取每个 bean 最近一次的创建耗时，从进入 createBean 到退出 createBean，包含依赖 bean 的创建耗时
+// 只包含至少成功创建过一次的 bean
+func (bc *BeanBeanFactory) StartupDuration() map[string]time.Duration {
+	bc.statMu.Lock()
+	defer bc.statMu.Unlock()
+	durations := make(map[string]time.Duration, len(bc.durationMap))
+	for beanName, d := range bc.durationMap {
+		durations[beanName] = d
+	}
+	return durations
+}
+
+// recordDuration 记录 bean 的创建耗时
+func (bc *BeanBeanFactory) recordDuration(beanName string, d time.Duration) {
+	bc.statMu.Lock()
+	defer bc.statMu.Unlock()
+	bc.durationMap[beanName] = d
+}
+
+// singletonEntry 已创建的单例 bean
+type singletonEntry struct {
+	name string
+	bean interface{}
+}
+
+// getSingletons 按创建顺序获取已创建的单例 bean 快照
+// 遍历时不持有锁，fn 中可以继续获取或者创建 bean
+func (bc *BeanBeanFactory) getSingletons() []singletonEntry {
+	bc.smu.RLock()
+	defer bc.smu.RUnlock()
+	entries := make([]singletonEntry, 0, len(bc.creationOrder))
+	for _, beanName := range bc.creationOrder {
+		if bean := bc.singletonMap[beanName]; bean != nil {
+			entries = append(entries, singletonEntry{name: beanName, bean: bean})
+		}
+	}
+	return entries
+}
+
+// ForEachSingleton 按创建顺序遍历调用时已创建的单例 bean，fn 返回 error 时停止遍历并返回该 error
+func (bc *BeanBeanFactory) ForEachSingleton(fn func(name string, bean interface{}) error) error {
+	for _, entry := range bc.getSingletons() {
+		if err := fn(entry.name, entry.bean); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ForEachSingletonConcurrent 每个单例 bean 启动一个 goroutine 调用 fn，等待全部结束后返回第一个 error
+func (bc *BeanBeanFactory) ForEachSingletonConcurrent(fn func(name string, bean interface{}) error) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for _, entry := range bc.getSingletons() {
+		wg.Add(1)
+		go func(entry singletonEntry) {
+			defer wg.Done()
+			if err := fn(entry.name, entry.bean); err != nil {
+				once.Do(func() {
+					firstErr = err
+				})
+			}
+		}(entry)
+	}
+	wg.Wait()
+	return firstErr
+}
+
+// GetBeanNameFor 根据单例 bean 实例反查 beanName，instance 必须是指针，按创建顺序返回第一个匹配的 beanName
+// 需要遍历所有已创建的单例 bean，适合 AOP 拦截器、调试等场景使用
+func (bc *BeanBeanFactory) GetBeanNameFor(instance interface{}) (string, bool) {
+	v := reflect.ValueOf(instance)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return "", false
+	}
+	for _, entry := range bc.getSingletons() {
+		bv := reflect.ValueOf(entry.bean)
+		// 结构体首个 field 的地址和结构体地址相同，因此还需要比较类型
+		if bv.Kind() == reflect.Ptr && bv.Type() == v.Type() && bv.Pointer() == v.Pointer() {
+			return entry.name, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,174 @@
+package gioc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type wiredA struct {
+	x int
+}
+
+type wiredC struct {
+	x int
+}
+
+type wiredB struct {
+	A     *wiredA `di:"wiredA"`
+	C     *wiredC `di:""`
+	Cache Cache   `di:"@qualifier=redis"`
+	Plain int
+}
+
+func TestInjectedDependencies(t *testing.T) {
+	ioc := NewIOC()
+	registerCaches(t, ioc)
+	ioc.MustRegisterAll(
+		NewClass("wiredA", (*wiredA)(nil), Singleton),
+		NewClass("wiredC", (*wiredC)(nil), Singleton),
+		NewClass("wiredB", (*wiredB)(nil), Singleton),
+	)
+	if deps := ioc.InjectedDependencies("wiredB"); len(deps) != 0 {
+		t.Fatalf("got %v before creation, want none", deps)
+	}
+	ioc.GetBean("wiredB")
+	want := map[string]string{"A": "wiredA", "C": "wiredC", "Cache": "redisCache"}
+	if deps := ioc.InjectedDependencies("wiredB"); !reflect.DeepEqual(deps, want) {
+		t.Fatalf("got %v, want %v", deps, want)
+	}
+}
+
+type lookupInner struct {
+	x int
+}
+
+type lookupOuter struct {
+	Inner lookupInner
+}
+
+func TestGetBeanNameFor(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("primary", (*wiredA)(nil), Singleton),
+		NewClass("secondary", (*wiredA)(nil), Singleton),
+		NewClass("outer", (*lookupOuter)(nil), Singleton),
+	)
+	for _, name := range []string{"primary", "secondary"} {
+		if got, ok := ioc.GetBeanNameFor(ioc.GetBean(name)); !ok || got != name {
+			t.Fatalf("got %v, %v, want %v", got, ok, name)
+		}
+	}
+	// 首个 field 与结构体地址相同，但类型不同，不能匹配
+	outer := ioc.GetBean("outer").(*lookupOuter)
+	if name, ok := ioc.GetBeanNameFor(&outer.Inner); ok {
+		t.Fatalf("first field matched bean %v", name)
+	}
+	if _, ok := ioc.GetBeanNameFor(&wiredA{}); ok {
+		t.Fatal("unmanaged instance matched a bean")
+	}
+	if _, ok := ioc.GetBeanNameFor(wiredA{}); ok {
+		t.Fatal("non-pointer instance matched a bean")
+	}
+}
+
+func TestGetBeansWhere(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("euCache", (*memCache)(nil), Singleton).SetQualifierAttribute("region", "eu"),
+		NewClass("euRedis", (*redisCache)(nil), Prototype).SetQualifierAttribute("region", "eu"),
+		NewClass("usCache", (*memCache)(nil), Singleton).SetQualifierAttribute("region", "us"),
+		NewClass("plain", (*wiredA)(nil), Singleton),
+	)
+	beans := ioc.GetBeansWhere(func(name string, attrs map[string]string, t reflect.Type) bool {
+		matched := attrs["region"] == "eu"
+		// pred 修改的是副本，不影响注册信息
+		attrs["region"] = "changed"
+		return matched
+	})
+	if len(beans) != 2 || beans["euCache"] != ioc.GetBean("euCache") || beans["euRedis"] == nil {
+		t.Fatalf("got %v, want euCache and euRedis", beans)
+	}
+	// 不满足条件的单例 bean 不会被创建
+	if n := ioc.GetCreatedSingletonCount(); n != 1 {
+		t.Fatalf("created %d singletons, want 1", n)
+	}
+	again := ioc.GetBeansWhere(func(name string, attrs map[string]string, t reflect.Type) bool {
+		return attrs["region"] == "eu"
+	})
+	if len(again) != 2 {
+		t.Fatalf("got %v after pred modified attrs, want 2 beans", again)
+	}
+}
+
+func TestGetBeanHolder(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("wiredA", (*wiredA)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	holder, err := ioc.GetBeanHolder("wiredA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if holder.Instance != ioc.GetBean("wiredA") {
+		t.Fatal("holder instance is not the singleton")
+	}
+	if holder.Name != "wiredA" || holder.Scope != Singleton || holder.Type != reflect.TypeOf(&wiredA{}) {
+		t.Fatalf("holder = %+v", holder)
+	}
+	if holder.CreatedAt.Before(before) || holder.CreatedAt.After(time.Now()) {
+		t.Fatalf("CreatedAt %v not within the GetBeanHolder call", holder.CreatedAt)
+	}
+	// 单例 bean 的创建时间不随获取改变
+	again, err := ioc.GetBeanHolder("wiredA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !again.CreatedAt.Equal(holder.CreatedAt) {
+		t.Fatalf("CreatedAt changed from %v to %v", holder.CreatedAt, again.CreatedAt)
+	}
+}
+
+func TestGetBeanHolderNotRegistered(t *testing.T) {
+	ioc := NewIOC()
+	if _, err := ioc.GetBeanHolder("missing"); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+}
+
+func TestRegistrationOrder(t *testing.T) {
+	ioc := newTopoIOC()
+	if got, want := ioc.RegistrationOrder(), []string{"zeta", "app", "repo", "cache", "db"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("RegistrationOrder() = %v, want %v", got, want)
+	}
+}
+
+func TestWithRegistrationOrderPreserved(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		opts []Option
+		want []string
+	}{
+		{"by name", nil, []string{"memCache", "redisCache"}},
+		{"by registration", []Option{WithRegistrationOrderPreserved()}, []string{"redisCache", "memCache"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ioc := NewIOC(tt.opts...)
+			ioc.MustRegisterAll(
+				NewClass("redisCache", (*redisCache)(nil), Singleton),
+				NewClass("memCache", (*memCache)(nil), Singleton),
+				NewClass("cacheRegistry", (*cacheRegistry)(nil), Singleton),
+			)
+			registry := ioc.GetBean("cacheRegistry").(*cacheRegistry)
+			var got []string
+			for _, cache := range registry.All {
+				got = append(got, cache.Name()+"Cache")
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("collection order %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,18 +1,32 @@
 package gioc
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"reflect"
+	"sort"
+	"strconv"
+	"sync"
 )
 
+// contextType context.Context 的 reflect.Type
+var contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+
+// anyType interface{} 的 reflect.Type
+var anyType = reflect.TypeOf((*interface{})(nil)).Elem()
+
 // BeanProcessor bean 处理器（Spring BeanPostProcessor bean 后置处理器简化版）
 type BeanProcessor interface {
-	// processPropertyValues 属性注入
-	processPropertyValues(wrapBean reflect.Value, t reflect.Type)
+	// processPropertyValues 属性注入，ctx 携带 bean 所属的创建链，获取依赖 bean 时需要使用
+	processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type)
 	// processBeforeInstantiation bean 初始化前处理函数，用户可以在这里自定义 bean 的创建逻辑
 	// 如果返回 bean != nil，那么不会再执行 createBean
 	processBeforeInstantiation(beanName string, t reflect.Type) interface{}
 	// postProcessAfterInitialization bean 初始化后处理函数，也是 AOP 的处理逻辑
 	processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{}
+	// processBeforeDestruction 单例 bean 销毁回调调用前的处理函数，用于清理处理器为 bean 维护的状态，不需要时实现为空方法
+	processBeforeDestruction(beanName string, bean interface{})
 }
 
 // PopulateBeanProcessor field 填充 bean 处理器
@@ -28,47 +42,166 @@ func NewPopulateBeanProcessor(bc *BeanBeanFactory) BeanProcessor {
 }
 
 // processPropertyValues 属性注入
-func (bp *PopulateBeanProcessor) processPropertyValues(wrapBean reflect.Value, t reflect.Type) {
-	// 扫描所有的 field
-	for i := 0; i < t.NumField(); i++ {
+func (bp *PopulateBeanProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
+	bp.populate(ctx, beanName, wrapBean, t, false)
+}
+
+// populate 属性注入，onlyZero 为 true 时只注入值为零值的 field，用于 Rewire 补充注入
+// 依赖 bean 与当前 bean 属于 ctx 所在的创建链，但不会收到 ctx 的取消以及其他值
+func (bp *PopulateBeanProcessor) populate(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type, onlyZero bool) {
+	ctx = dependencyContext(ctx)
+	// 记录每个 field 实际注入的 beanName，外部对象没有 beanName，不记录
+	injected := map[string]string{}
+	if onlyZero {
+		injected = bp.bc.InjectedDependencies(beanName)
+	}
+	if beanName != "" {
+		defer bp.bc.setInjectedDependencies(beanName, injected)
+	}
+	// 按 order 注解的顺序扫描所有的 field
+	for _, i := range getFieldOrder(t) {
 		field := t.Field(i)
+		if err := checkLegacyBeanNameTag(field); err != nil {
+			panic(fmt.Errorf("bean %v: %w", beanName, err))
+		}
+		// 已经设置过的 field 保持不变
+		if onlyZero && !wrapBean.Field(i).IsZero() {
+			continue
+		}
+		// 不满足 diif 条件的 field 不注入
+		if ok, err := bp.bc.isGuardSatisfied(field); err != nil {
+			panic(err)
+		} else if !ok {
+			continue
+		}
+		// 配置属性绑定，不是 bean，单独处理
+		if prefix, ok := field.Tag.Lookup(ConfigPropsTag); ok {
+			bp.bc.bindConfigProps(wrapBean.Field(i), field, prefix)
+			continue
+		}
+		// 作用域 context 不是 bean，单独处理
+		if _, ok := field.Tag.Lookup(ScopeContextTag); ok {
+			wrapBean.Field(i).Set(reflect.ValueOf(bp.bc.getScopeContext(beanName, t, field)))
+			continue
+		}
+		// 返回 error 的提供者函数，调用时才获取 bean
+		if isAutowired(field) && isErrorProvider(field.Type) {
+			bp.bc.injectErrorProvider(wrapBean.Field(i), field, bp.bc.getInjectQualifier(beanName, field))
+			continue
+		}
+		// 延迟获取函数，调用时才获取 bean
+		if isAutowired(field) && hasAutowiredOption(field, LazyOption) && isLazyFunc(field.Type) {
+			bp.bc.injectLazyFunc(wrapBean.Field(i), field, bp.bc.getInjectQualifier(beanName, field))
+			continue
+		}
+		// 键控提供者函数，调用时按 key 获取 bean
+		if isAutowired(field) && isKeyedProvider(field.Type) {
+			bp.bc.injectKeyedProvider(wrapBean.Field(i), field, bp.bc.getInjectQualifier(beanName, field))
+			continue
+		}
+		// 集合 field，注入所有能够赋值给元素类型的 bean，指定的 beanName 已经注册时按 beanName 注入
+		if bp.bc.isCollectionInjection(field) {
+			bp.bc.injectCollection(ctx, beanName, wrapBean.Field(i), field)
+			continue
+		}
 		// field 的 reflect.Type 类型信息
 		ftPtr := field.Type
 		// field 的 非 ptr type
-		var ft reflect.Type
-		if ftPtr.Kind() == reflect.Ptr {
-			ft = ftPtr.Elem()
-		} else {
-			// 不允许非 ptr 结构体注入
-			if !bp.bc.isAllowPopulateStructBean() {
-				continue
-			}
-			ft = ftPtr
+		ft, ok := bp.bc.getFieldInjectType(field)
+		if !ok {
+			continue
 		}
-		// 非 wrapBean，那么直接跳过
-		if !isBean(ft) {
+		// 不存在 di 注解，那么当前 field 不需要注入，那么跳过
+		if !isAutowired(field) {
 			continue
 		}
-		// 获取注入类型
+		// 获取注入点指定的作用域
 		fieldBeanType := getFieldBeanType(field)
-		// 不存在 di 注解，那么当前 field 不需要注入，那么跳过2
-		if fieldBeanType == Invalid {
+		// 获取注入限定符
+		qualifier := bp.bc.getInjectQualifier(beanName, field)
+		// 是否延迟注入
+		lazy := hasAutowiredOption(field, LazyOption)
+		// 是否可选注入，bean 不存在时保持零值
+		optional := hasAutowiredOption(field, OptionalOption)
+		// 延迟注入，注入代理，目标 bean 在第一次调用时才会创建
+		if lazy {
+			bp.bc.injectLazy(wrapBean.Field(i), field, qualifier)
 			continue
 		}
+		// 存在限定符，那么从已注册的 bean 中按 类型 + 限定符 选择 bean 注入
+		if !qualifier.isEmpty() {
+			fieldBeanName, err := bp.bc.getBeanNameWithQualifier(ftPtr, qualifier)
+			if err != nil {
+				if optional {
+					continue
+				}
+				panic(err)
+			}
+			fieldBean := bp.bc.doGetBeanContext(ctx, fieldBeanName, false)
+			// 接口 field 不注入类型化的 nil，保持真正的 nil 接口
+			if fieldBean == nil || ftPtr.Kind() == reflect.Interface && isNilBean(fieldBean) {
+				continue
+			}
+			if ftPtr.Kind() == reflect.Interface {
+				fieldBean = bp.bc.adviseForInterface(fieldBeanName, ftPtr, fieldBean)
+			}
+			wrapBean.Field(i).Set(reflect.ValueOf(fieldBean))
+			injected[field.Name] = fieldBeanName
+			continue
+		}
+		// 空接口可以是任意 bean，无法按类型选择，必须指定 beanName
+		if isEmptyInterface(ftPtr) && getBeanName(field) == "" {
+			panic(fmt.Errorf("field %v.%v: %v field requires a beanName, e.g. di:\"name\"", t.Name(), field.Name, ftPtr))
+		}
 		// 获取 field 对应注解的 beanName
-		fieldBeanName := getFieldBeanName(bp.bc, field, ft)
+		fieldBeanName, err := getFieldBeanName(bp.bc, field, ft)
+		notFound := errors.Is(err, ErrBeanNotFound)
+		if err != nil && !notFound {
+			panic(fmt.Errorf("field %v.%v: %w", t.Name(), field.Name, err))
+		}
+		// 可选注入的 bean 不存在时不会自动注册
+		if optional && notFound {
+			continue
+		}
+		// 接口无法实例化，没有注册实现时不能自动注册
+		if ftPtr.Kind() == reflect.Interface && notFound {
+			panic(fmt.Errorf("field %v.%v: interface %v bean %v: %w", t.Name(), field.Name, ftPtr, fieldBeanName, err))
+		}
+		// 值 field 只能注入已经注册的值，无法自动注册
+		if !isBean(ft) && notFound {
+			panic(fmt.Errorf("field %v.%v: value %v bean %v: %w", t.Name(), field.Name, ftPtr, fieldBeanName, err))
+		}
 		// 判断是否需要注册到 beanFactory 中
-		if !bp.bc.isRegistered(fieldBeanName) {
-			// 注册到 beanFactory 中
-			_ = bp.bc.Register(NewClass(fieldBeanName, ftPtr, fieldBeanType))
+		if notFound {
+			// 注册到 beanFactory 中，注入点没有指定作用域时注册为单例
+			autoBeanType := fieldBeanType
+			if autoBeanType == Invalid {
+				autoBeanType = Singleton
+			}
+			_ = bp.bc.Register(NewClass(fieldBeanName, ftPtr, autoBeanType))
+		}
+		if bp.bc.opts.devMode {
+			bp.bc.checkInjection(beanName, field, fieldBeanName, fieldBeanType)
 		}
 		var fieldBean interface{}
-		if isStructBean(ftPtr, ft) {
-			fieldBean = bp.bc.GetNewBean(fieldBeanName)
-
-		} else {
-			fieldBean = bp.bc.GetBean(fieldBeanName)
+		// 接口 field，bean 本身就是接口的实现，直接赋值即可
+		if ftPtr.Kind() == reflect.Interface {
+			fieldBean = bp.bc.getBeanWithScope(ctx, fieldBeanName, fieldBeanType, false)
+			// bean 为类型化的 nil 时不注入，保持 field 为 nil 接口，== nil 判断才能生效
+			if !isNilBean(fieldBean) {
+				// 不能 Addr()，bean 的类型必须实现了接口，否则 Set 会 panic 且信息不明确，空接口不需要检查
+				if !isEmptyInterface(ftPtr) && !reflect.TypeOf(fieldBean).Implements(ftPtr) {
+					panic(fmt.Errorf("field %v.%v: bean %v of type %T does not implement %v", t.Name(), field.Name, fieldBeanName, fieldBean, ftPtr))
+				}
+				// 以接口注入时应用该接口的通知，其他接口的 field 注入原始 bean
+				fieldBean = bp.bc.adviseForInterface(fieldBeanName, ftPtr, fieldBean)
+				wrapBean.Field(i).Set(reflect.ValueOf(fieldBean))
+				injected[field.Name] = fieldBeanName
+			}
+			continue
 		}
+		// 非 ptr 结构体 field 注入全新的 bean，map 和 slice 是引用类型，注入共享的 bean 即可
+		fieldBean = bp.bc.getBeanWithScope(ctx, fieldBeanName, fieldBeanType, isStructBean(ftPtr, ft) && ft.Kind() == reflect.Struct)
 		// 调用 GetBean() 获取 field wrapBean，走 container 的逻辑
 		// 获取不到 wrapBean，那么跳过
 		if fieldBean == nil {
@@ -87,9 +220,105 @@ func (bp *PopulateBeanProcessor) processPropertyValues(wrapBean reflect.Value, t
 			// field ptr，那么需要 fieldBean 是 ptr wrapBean，这里需要先进行 Elem()，然后 Addr() 返回地址，赋值给 field
 			wrapBean.Field(i).Set(fieldBeanValue.Addr())
 		}
+		injected[field.Name] = fieldBeanName
 	}
 }
 
+// getScopeContext 获取 bean 所属作用域的 context，bean 不属于绑定 context 的作用域时 panic
+func (bc *BeanBeanFactory) getScopeContext(beanName string, t reflect.Type, field reflect.StructField) context.Context {
+	if field.Type != contextType {
+		panic(fmt.Errorf("field %v.%v: %v tag requires type %v, got %v", t.Name(), field.Name, ScopeContextTag, contextType, field.Type))
+	}
+	bc.mu.RLock()
+	c := bc.scMap[bc.btMap[beanName]]
+	bc.mu.RUnlock()
+	if sc, ok := c.(*ScopeContainer); ok {
+		if ctxScope, ok := sc.scope.(ContextScope); ok {
+			return ctxScope.Context()
+		}
+	}
+	panic(fmt.Errorf("field %v.%v: bean %v is not in a context-bound scope", t.Name(), field.Name, beanName))
+}
+
+// getFieldInjectType 获取 field 的非 ptr type，field 不能作为 bean 注入时返回 false
+func (bc *BeanBeanFactory) getFieldInjectType(field reflect.StructField) (reflect.Type, bool) {
+	ftPtr := field.Type
+	var ft reflect.Type
+	if ftPtr.Kind() == reflect.Ptr {
+		ft = ftPtr.Elem()
+	} else if ftPtr.Kind() == reflect.Interface {
+		// 接口 field，注入的是实现了该接口的 bean
+		ft = ftPtr
+	} else if ftPtr.Kind() == reflect.Map || ftPtr.Kind() == reflect.Slice {
+		// map 和 slice field，注入的是共享的 map 和 slice bean
+		ft = ftPtr
+	} else if ftPtr.Kind() != reflect.Struct {
+		// 基本类型等 field 只能注入通过 RegisterValueBean 注册的值，必须指定 beanName
+		return ftPtr, getBeanName(field) != ""
+	} else {
+		// 不允许非 ptr 结构体注入，注解中指定了 struct 选项的 field 除外
+		if !bc.isAllowPopulateStructBean() && !hasAutowiredOption(field, StructOption) {
+			return nil, false
+		}
+		ft = ftPtr
+	}
+	// 非 bean，那么直接跳过
+	if !isBean(ft) {
+		return nil, false
+	}
+	return ft, true
+}
+
+// fieldOrderCache 缓存每个 struct 类型的 field 注入顺序，避免原型 bean 每次注入都重新解析 order 注解
+var fieldOrderCache sync.Map
+
+// getFieldOrder 获取 field 的注入顺序，按 order 注解升序排列，没有 order 注解的 field 视为 0，相同时保持声明顺序
+// 返回的切片会被缓存共享，调用方不能修改
+func getFieldOrder(t reflect.Type) []int {
+	if indexes, ok := fieldOrderCache.Load(t); ok {
+		return indexes.([]int)
+	}
+	indexes := make([]int, t.NumField())
+	orders := make([]int, t.NumField())
+	for i := range indexes {
+		indexes[i] = i
+		field := t.Field(i)
+		value, exist := field.Tag.Lookup(OrderTag)
+		if !exist {
+			continue
+		}
+		order, err := strconv.Atoi(value)
+		if err != nil {
+			panic(fmt.Errorf("field %v.%v: invalid %v tag %q", t.Name(), field.Name, OrderTag, value))
+		}
+		orders[i] = order
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		return orders[indexes[i]] < orders[indexes[j]]
+	})
+	fieldOrderCache.Store(t, indexes)
+	return indexes
+}
+
+// isEmptyInterface 判断是否是 interface{} 类型
+func isEmptyInterface(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface && t.NumMethod() == 0
+}
+
+// isNilBean 判断 bean 是否为 nil，包括 nil ptr、map、slice 等类型化的 nil
+// 类型化的 nil 赋值给接口 field 后接口不为 nil，== nil 判断会失效，因此注入前需要识别
+func isNilBean(bean interface{}) bool {
+	if bean == nil {
+		return true
+	}
+	v := reflect.ValueOf(bean)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
 // isStructBean 判断是否是 struct bean（非 ptr）
 func isStructBean(ftPtr, ft reflect.Type) bool {
 	return ftPtr == ft
@@ -105,9 +334,15 @@ func (bp *PopulateBeanProcessor) processAfterInitialization(beanName string, bea
 	return nil
 }
 
+// processBeforeDestruction
+func (bp *PopulateBeanProcessor) processBeforeDestruction(beanName string, bean interface{}) {
+}
+
 // AopBeanProcessor aop bean 处理器
 type AopBeanProcessor struct {
 	bc *BeanBeanFactory
+	// 保护 earlyProxyReferences，bean 可能在多个 goroutine 中并发创建
+	mu sync.Mutex
 	// 存储早期对象 AOP 处理过的 beanName 列表
 	earlyProxyReferences map[string]interface{}
 }
@@ -121,7 +356,7 @@ func NewAopBeanProcessor(bc *BeanBeanFactory) BeanProcessor {
 }
 
 // processPropertyValues
-func (bp *AopBeanProcessor) processPropertyValues(wrapBean reflect.Value, t reflect.Type) {
+func (bp *AopBeanProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
 }
 
 // processBeforeInstantiation
@@ -132,14 +367,26 @@ func (bp *AopBeanProcessor) processBeforeInstantiation(beanName string, t reflec
 // processAfterInitialization
 func (bp *AopBeanProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
 	// 作为早期对象的时候已经处理过了
-	if bp.earlyProxyReferences[beanName] != nil {
+	bp.mu.Lock()
+	processed := bp.earlyProxyReferences[beanName] != nil
+	bp.mu.Unlock()
+	if processed {
 		return bean
 	}
 	return bp.wrapIfNecessary(beanName, bean)
 }
 
+// processBeforeDestruction 移除 bean 的 AOP 处理记录，bean 重新创建时需要重新处理
+func (bp *AopBeanProcessor) processBeforeDestruction(beanName string, bean interface{}) {
+	bp.mu.Lock()
+	defer bp.mu.Unlock()
+	delete(bp.earlyProxyReferences, beanName)
+}
+
 // wrapIfNecessary AOP 处理
 func (bp *AopBeanProcessor) wrapIfNecessary(beanName string, bean interface{}) interface{} {
+	bp.mu.Lock()
 	bp.earlyProxyReferences[beanName] = struct{}{}
+	bp.mu.Unlock()
 	return bean
 }
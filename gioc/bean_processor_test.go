@@ -0,0 +1,157 @@
+package gioc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+type orderedFirst struct {
+	x int
+}
+
+type orderedSecond struct {
+	x int
+}
+
+// orderedBean 声明顺序与 order 注解相反，orderedFirst 必须先注入
+type orderedBean struct {
+	Second *orderedSecond `di:"orderedSecond" order:"2"`
+	First  *orderedFirst  `di:"orderedFirst" order:"1"`
+}
+
+func TestFieldInjectionOrder(t *testing.T) {
+	ioc := NewIOC()
+	var created []string
+	err := ioc.RegisterBeanProcessorFunc(PhasePropertyValues, func(beanName string, bean interface{}) interface{} {
+		created = append(created, beanName)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioc.MustRegisterAll(
+		NewClass("orderedFirst", (*orderedFirst)(nil), Singleton),
+		NewClass("orderedSecond", (*orderedSecond)(nil), Singleton),
+		NewClass("orderedBean", (*orderedBean)(nil), Singleton),
+	)
+	bean := ioc.GetBean("orderedBean").(*orderedBean)
+	if bean.First == nil || bean.Second == nil {
+		t.Fatalf("fields not injected: %+v", bean)
+	}
+	// 依赖在注入 field 时创建，创建顺序即注入顺序
+	var deps []string
+	for _, name := range created {
+		if name != "orderedBean" {
+			deps = append(deps, name)
+		}
+	}
+	if want := []string{"orderedFirst", "orderedSecond"}; !reflect.DeepEqual(deps, want) {
+		t.Fatalf("injection order %v, want %v", deps, want)
+	}
+}
+
+func TestGetFieldOrder(t *testing.T) {
+	type mixed struct {
+		A int `order:"1"`
+		B int
+		C int `order:"-1"`
+		D int
+	}
+	if got, want := getFieldOrder(reflect.TypeOf(mixed{})), []int{2, 1, 3, 0}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("getFieldOrder = %v, want %v", got, want)
+	}
+}
+
+func TestGetFieldOrderInvalidTag(t *testing.T) {
+	type invalid struct {
+		A int `order:"first"`
+	}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic for invalid order tag")
+		}
+	}()
+	getFieldOrder(reflect.TypeOf(invalid{}))
+}
+
+type structConfig struct {
+	Name string
+}
+
+func (c *structConfig) AfterPropertiesSet(ctx context.Context) error {
+	c.Name = "config"
+	return nil
+}
+
+type structUser struct {
+	Tagged   structConfig `di:"structConfig,struct"`
+	Untagged structConfig `di:"structConfig"`
+}
+
+func TestStructOption(t *testing.T) {
+	ioc := NewIOC(WithAllowPopulateStructBean(false))
+	ioc.MustRegisterAll(
+		NewClass("structConfig", (*structConfig)(nil), Singleton),
+		NewClass("structUser", (*structUser)(nil), Singleton),
+	)
+	user := ioc.GetBean("structUser").(*structUser)
+	if user.Tagged.Name != "config" {
+		t.Fatalf("field with struct option not injected: %+v", user.Tagged)
+	}
+	if user.Untagged.Name != "" {
+		t.Fatalf("field without struct option injected while disabled globally: %+v", user.Untagged)
+	}
+}
+
+// Notifier 没有任何实现注册的接口
+type Notifier interface {
+	Notify(msg string)
+}
+
+type optionalUser struct {
+	Notifier Notifier `di:",optional"`
+	Cache    Cache    `di:"nilCache,optional"`
+}
+
+func TestOptionalInterfaceFieldIsNil(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(newNilCacheClass(), NewClass("optionalUser", (*optionalUser)(nil), Singleton))
+	user, err := ioc.GetBeanE("optionalUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := user.(*optionalUser); u.Notifier != nil || u.Cache != nil {
+		t.Fatalf("unresolved optional interface fields should be nil, got %#v and %#v", u.Notifier, u.Cache)
+	}
+}
+
+// newNilCacheClass 返回类型化 nil 的 bean
+func newNilCacheClass() *Class {
+	return NewClass("nilCache", (*memCache)(nil), Singleton).SetSupplier(func() interface{} {
+		return (*memCache)(nil)
+	})
+}
+
+func TestOptionalInterfaceConstructorParamIsNil(t *testing.T) {
+	ioc := NewIOC()
+	var cache Cache = &memCache{}
+	var notifier OptionalParam[Notifier]
+	constructor := func(c Cache, n OptionalParam[Notifier]) *optionalUser {
+		cache, notifier = c, n
+		return &optionalUser{}
+	}
+	ioc.MustRegisterAll(
+		newNilCacheClass(),
+		NewClass("optionalUser", nil, Singleton).SetConstructor(constructor),
+	)
+	if _, err := ioc.GetBeanE("optionalUser"); err != nil {
+		t.Fatal(err)
+	}
+	if cache != nil {
+		t.Fatalf("typed nil bean should be passed as a nil interface, got %#v", cache)
+	}
+	if notifier.Present() || notifier.Value() != nil {
+		t.Fatalf("unresolved optional interface parameter should be nil, got %#v", notifier.Value())
+	}
+}
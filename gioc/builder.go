@@ -0,0 +1,144 @@
+package gioc
+
+import (
+	"io"
+	"reflect"
+)
+
+// BeanFactoryBuilder 链式构建 IOC 容器，Build 之后返回的容器不允许再注册 bean，例如
+//
+//	ioc, err := gioc.NewBuilder().WithOptions(gioc.WithFailFast(false)).
+//		Register("a", (*A)(nil), gioc.Singleton).
+//		Register("b", (*B)(nil), gioc.Singleton).
+//		Build()
+type BeanFactoryBuilder struct {
+	opts       []Option
+	classes    []*Class
+	processors []*Class
+}
+
+// NewBuilder 实例化一个容器构建器
+func NewBuilder() *BeanFactoryBuilder {
+	return &BeanFactoryBuilder{}
+}
+
+// WithOptions 追加容器的可选参数
+func (b *BeanFactoryBuilder) WithOptions(opts ...Option) *BeanFactoryBuilder {
+	b.opts = append(b.opts, opts...)
+	return b
+}
+
+// Register 添加一个 bean，Build 时按添加顺序注册
+func (b *BeanFactoryBuilder) Register(beanName string, i interface{}, beanType BeanType) *BeanFactoryBuilder {
+	return b.RegisterClass(NewClass(beanName, i, beanType))
+}
+
+// RegisterClass 添加一个 bean，用于需要设置构造函数、限定符等信息的 bean
+func (b *BeanFactoryBuilder) RegisterClass(class *Class) *BeanFactoryBuilder {
+	b.classes = append(b.classes, class)
+	return b
+}
+
+// RegisterBeanProcessor 添加一个 bean 处理器，Build 时在注册 bean 之前注册
+func (b *BeanFactoryBuilder) RegisterBeanProcessor(class *Class) *BeanFactoryBuilder {
+	b.processors = append(b.processors, class)
+	return b
+}
+
+// Build 注册所有 bean 并调用 Refresh，返回冻结的容器，冻结后注册、替换 bean 的方法返回 ErrFrozen，GetBeanFactory 返回只读的 bean 工厂
+// 任意一步失败时返回 error
+func (b *BeanFactoryBuilder) Build() (*IOC, error) {
+	ioc := NewIOC(b.opts...)
+	for _, class := range b.processors {
+		if err := ioc.RegisterBeanProcessor(class); err != nil {
+			return nil, err
+		}
+	}
+	for _, class := range b.classes {
+		if err := ioc.Register(class); err != nil {
+			return nil, err
+		}
+	}
+	if err := ioc.Refresh(); err != nil {
+		return nil, err
+	}
+	ioc.frozen = true
+	return ioc, nil
+}
+
+// frozenBeanFactory 冻结容器的只读 bean 工厂，注册、替换 bean 的方法返回 ErrFrozen，其余方法委托给原 bean 工厂
+type frozenBeanFactory struct {
+	BeanFactory
+}
+
+func (f frozenBeanFactory) Register(class *Class) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterBeanProcessor(class *Class) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterBeanProcessorFunc(phase ProcessorPhase, fn func(beanName string, bean interface{}) interface{}) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) Rebind(def BeanDefinition) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterInterfaceBean(beanName string, ifaceType reflect.Type, supplier func() interface{}) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterFromStructTags(vals ...interface{}) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) SetBeanScope(beanName string, newScope BeanType, opts ...SetScopeOption) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterValueBean(beanName string, value interface{}) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterScope(name string, scope Scope) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterLazyProxy(iface interface{}, factory LazyProxyFactory) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterBeanAlias(alias string, beanName string, opts ...AliasOption) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterInterfaceAdvice(beanName string, iface interface{}, advice InterfaceAdvice) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) RegisterType(name string, i interface{}) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) LoadDefinitions(r io.Reader, format string) error {
+	return ErrFrozen
+}
+
+// GetOrCreate 只获取已经注册的 bean，bean 未注册时返回 ErrFrozen
+func (f frozenBeanFactory) GetOrCreate(beanName string, class *Class) (interface{}, error) {
+	if !f.isRegistered(beanName) {
+		return nil, ErrFrozen
+	}
+	return f.BeanFactory.GetOrCreate(beanName, class)
+}
+
+func (f frozenBeanFactory) Decorate(beanName string, decorator Decorator) error {
+	return ErrFrozen
+}
+
+func (f frozenBeanFactory) Swap(beanName string, newInstance interface{}) error {
+	return ErrFrozen
+}
@@ -0,0 +1,80 @@
+package gioc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type frozenBean struct{}
+
+func newFrozenIOC(t *testing.T) *IOC {
+	ioc, err := NewBuilder().Register("frozenBean", (*frozenBean)(nil), Singleton).Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ioc
+}
+
+func TestFrozenRejectsMutation(t *testing.T) {
+	ioc := newFrozenIOC(t)
+	defer ioc.Close()
+	identity := func(i interface{}) interface{} { return i }
+	mutations := map[string]func(ioc *IOC) error{
+		"Register":               func(ioc *IOC) error { return ioc.Register(NewClass("other", (*frozenBean)(nil), Singleton)) },
+		"RegisterFromStructTags": func(ioc *IOC) error { return ioc.RegisterFromStructTags(&frozenBean{}) },
+		"SetBeanScope":           func(ioc *IOC) error { return ioc.SetBeanScope("frozenBean", Prototype) },
+		"RegisterBeanProcessor": func(ioc *IOC) error {
+			return ioc.RegisterBeanProcessor(NewClass("bp", (*noopProcessor)(nil), Singleton))
+		},
+		"RegisterBeanProcessorFunc": func(ioc *IOC) error {
+			return ioc.RegisterBeanProcessorFunc(PhaseAfterInitialization, func(_ string, b interface{}) interface{} { return b })
+		},
+		"RegisterValueBean": func(ioc *IOC) error { return ioc.RegisterValueBean("value", &frozenBean{}) },
+		"RegisterScope":     func(ioc *IOC) error { return ioc.RegisterScope("request", NewRequestScope(context.Background())) },
+		"RegisterLazyProxy": func(ioc *IOC) error {
+			return ioc.RegisterLazyProxy((*Handler)(nil), func(func() interface{}) interface{} { return nil })
+		},
+		"RegisterBeanAlias":       func(ioc *IOC) error { return ioc.RegisterBeanAlias("alias", "frozenBean") },
+		"RegisterInterfaceAdvice": func(ioc *IOC) error { return ioc.RegisterInterfaceAdvice("frozenBean", (*Handler)(nil), identity) },
+		"RegisterType":            func(ioc *IOC) error { return ioc.RegisterType("frozenBean", (*frozenBean)(nil)) },
+		"LoadDefinitions":         func(ioc *IOC) error { return ioc.LoadDefinitions(strings.NewReader("{}"), "json") },
+		"Rebind":                  func(ioc *IOC) error { return ioc.Rebind(BeanDefinition{Name: "frozenBean"}) },
+		"Decorate":                func(ioc *IOC) error { return ioc.Decorate("frozenBean", identity) },
+		"Swap":                    func(ioc *IOC) error { return ioc.Swap("frozenBean", &frozenBean{}) },
+		"GetOrCreate": func(ioc *IOC) error {
+			_, err := ioc.GetOrCreate("other", NewClass("other", (*frozenBean)(nil), Singleton))
+			return err
+		},
+	}
+	for name, mutate := range mutations {
+		if err := mutate(ioc); !errors.Is(err, ErrFrozen) {
+			t.Errorf("%v() = %v, want ErrFrozen", name, err)
+		}
+	}
+	if _, err := ioc.GetOrCreate("frozenBean", nil); err != nil {
+		t.Errorf("GetOrCreate() of a registered bean = %v", err)
+	}
+}
+
+func TestFrozenBeanFactoryRejectsMutation(t *testing.T) {
+	ioc := newFrozenIOC(t)
+	defer ioc.Close()
+	bf := ioc.GetBeanFactory()
+	if err := bf.Register(NewClass("other", (*frozenBean)(nil), Singleton)); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Register() = %v, want ErrFrozen", err)
+	}
+	if err := bf.Swap("frozenBean", &frozenBean{}); !errors.Is(err, ErrFrozen) {
+		t.Errorf("Swap() = %v, want ErrFrozen", err)
+	}
+	if _, err := bf.GetOrCreate("other", NewClass("other", (*frozenBean)(nil), Singleton)); !errors.Is(err, ErrFrozen) {
+		t.Errorf("GetOrCreate() = %v, want ErrFrozen", err)
+	}
+	if bf.GetBean("frozenBean") != ioc.GetBean("frozenBean") {
+		t.Error("frozen bean factory returned a different bean")
+	}
+	if ioc.GetRegisteredBeanCount() != 1 {
+		t.Errorf("GetRegisteredBeanCount() = %v, want 1", ioc.GetRegisteredBeanCount())
+	}
+}
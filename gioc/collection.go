@@ -0,0 +1,115 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// isCollection 判断 t 是否为元素是 bean 的 slice、数组或者 key 为 beanName 的 map，例如 []Handler、[3]Handler、map[string]*A
+func isCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return isCollectionElem(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isCollectionElem(t.Elem())
+	}
+	return false
+}
+
+// isCollectionInjection 判断 field 是否按集合注入所有匹配的 bean
+// di 注解指定了 beanName 并且该 bean 已经注册时按 beanName 注入，例如 di:"handlers" 注入注册的 map[string]Handler bean，
+// 只有没有指定 beanName 或者指定的 bean 不存在时才收集所有匹配的 bean
+func (bc *BeanBeanFactory) isCollectionInjection(field reflect.StructField) bool {
+	if !isAutowired(field) || !isCollection(field.Type) {
+		return false
+	}
+	name := getBeanName(field)
+	return name == "" || !bc.isRegistered(name)
+}
+
+// isCollectionElem 集合的元素只能是接口或者结构体指针
+func isCollectionElem(t reflect.Type) bool {
+	return t.Kind() == reflect.Interface || (t.Kind() == reflect.Ptr && t.Elem().Kind() == reflect.Struct)
+}
+
+// injectCollection 将所有能够赋值给元素类型的 bean 注入到集合 field，按 beanName 排序，开启 WithRegistrationOrderPreserved 时按注册顺序排序，不包含 bean 自身
+// field 已经存在的 slice 或者 map 不会被覆盖，bean 追加到 slice 末尾，map 中已经存在的 key 保持不变，
+// 因此 bean 可以在构造时预先放入自己的元素
+func (bc *BeanBeanFactory) injectCollection(ctx context.Context, beanName string, fieldValue reflect.Value, field reflect.StructField) {
+	if field.Type.Kind() == reflect.Array {
+		bc.injectArray(ctx, beanName, fieldValue, field)
+		return
+	}
+	ft := field.Type
+	et := ft.Elem()
+	beanType := getFieldBeanType(field)
+	collection := fieldValue
+	if collection.IsNil() {
+		if ft.Kind() == reflect.Slice {
+			collection = reflect.MakeSlice(ft, 0, 0)
+		} else {
+			collection = reflect.MakeMap(ft)
+		}
+	}
+	for _, name := range bc.getBeanNamesAssignableTo(et) {
+		if name == beanName {
+			continue
+		}
+		if ft.Kind() == reflect.Map && collection.MapIndex(reflect.ValueOf(name)).IsValid() {
+			continue
+		}
+		bean := bc.getBeanWithScope(ctx, name, beanType, false)
+		if bean == nil {
+			continue
+		}
+		bean = bc.adviseForInterface(name, et, bean)
+		beanValue := reflect.ValueOf(bean)
+		if !beanValue.Type().AssignableTo(et) {
+			panic(fmt.Errorf("field %v: bean %v of type %T is not assignable to %v", field.Name, name, bean, et))
+		}
+		if ft.Kind() == reflect.Slice {
+			collection = reflect.Append(collection, beanValue)
+		} else {
+			collection.SetMapIndex(reflect.ValueOf(name), beanValue)
+		}
+	}
+	// 没有匹配的 bean 时保持 nil，之后注册了新的 bean 可以通过 Rewire 注入
+	if collection.Len() > 0 {
+		fieldValue.Set(collection)
+	}
+}
+
+// injectArray 将所有能够赋值给元素类型的 bean 按集合注入的顺序注入到数组 field，不包含 bean 自身
+// 匹配的 bean 数量必须等于数组长度，否则 panic；可选注入时没有匹配的 bean 保持零值
+func (bc *BeanBeanFactory) injectArray(ctx context.Context, beanName string, fieldValue reflect.Value, field reflect.StructField) {
+	ft := field.Type
+	et := ft.Elem()
+	beanType := getFieldBeanType(field)
+	var beanNames []string
+	for _, name := range bc.getBeanNamesAssignableTo(et) {
+		if name != beanName {
+			beanNames = append(beanNames, name)
+		}
+	}
+	if len(beanNames) == 0 && hasAutowiredOption(field, OptionalOption) {
+		return
+	}
+	if len(beanNames) != ft.Len() {
+		panic(fmt.Errorf("field %v: array %v needs %v beans, got %v: %v", field.Name, ft, ft.Len(), len(beanNames), beanNames))
+	}
+	array := reflect.New(ft).Elem()
+	for i, name := range beanNames {
+		bean := bc.getBeanWithScope(ctx, name, beanType, false)
+		if bean == nil {
+			panic(fmt.Errorf("field %v: bean %v of array %v is nil", field.Name, name, ft))
+		}
+		bean = bc.adviseForInterface(name, et, bean)
+		beanValue := reflect.ValueOf(bean)
+		if !beanValue.Type().AssignableTo(et) {
+			panic(fmt.Errorf("field %v: bean %v of type %T is not assignable to %v", field.Name, name, bean, et))
+		}
+		array.Index(i).Set(beanValue)
+	}
+	fieldValue.Set(array)
+}
@@ -0,0 +1,141 @@
+package gioc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type seededCache struct {
+	x int
+}
+
+func (*seededCache) Name() string { return "seeded" }
+
+type cacheRegistry struct {
+	All    []Cache          `di:""`
+	ByName map[string]Cache `di:""`
+}
+
+func TestCollectionKeepsPreseededEntries(t *testing.T) {
+	ioc := NewIOC()
+	registerCaches(t, ioc)
+	seeded := &seededCache{}
+	override := &seededCache{}
+	constructor := func() *cacheRegistry {
+		return &cacheRegistry{
+			All:    []Cache{seeded},
+			ByName: map[string]Cache{"custom": seeded, "redisCache": override},
+		}
+	}
+	if err := ioc.Register(NewClass("cacheRegistry", nil, Singleton).SetConstructor(constructor)); err != nil {
+		t.Fatal(err)
+	}
+	registry := ioc.GetBean("cacheRegistry").(*cacheRegistry)
+	mem, redis := ioc.GetBean("memCache"), ioc.GetBean("redisCache")
+	// 预先放入的元素在前，容器发现的 bean 按 beanName 排序追加
+	if len(registry.All) != 3 || registry.All[0] != seeded || registry.All[1] != mem || registry.All[2] != redis {
+		t.Fatalf("got %v, want [seeded mem redis]", registry.All)
+	}
+	// map 中已经存在的 key 保持不变
+	if len(registry.ByName) != 3 || registry.ByName["custom"] != seeded || registry.ByName["redisCache"] != override || registry.ByName["memCache"] != mem {
+		t.Fatalf("got %v", registry.ByName)
+	}
+}
+
+type Handler interface {
+	Handle() string
+}
+
+type handlerA struct{ x int }
+type handlerB struct{ x int }
+type handlerC struct{ x int }
+
+func (*handlerA) Handle() string { return "a" }
+func (*handlerB) Handle() string { return "b" }
+func (*handlerC) Handle() string { return "c" }
+
+type handlerChain struct {
+	Handlers [3]Handler `di:""`
+}
+
+func newHandlerIOC(classes ...*Class) *IOC {
+	ioc := NewIOC(WithFailFast(false))
+	classes = append(classes, NewClass("handlerChain", (*handlerChain)(nil), Singleton))
+	ioc.MustRegisterAll(classes...)
+	return ioc
+}
+
+func TestArrayCollection(t *testing.T) {
+	ioc := newHandlerIOC(
+		NewClass("handlerC", (*handlerC)(nil), Singleton),
+		NewClass("handlerA", (*handlerA)(nil), Singleton),
+		NewClass("handlerB", (*handlerB)(nil), Singleton),
+	)
+	bean, err := ioc.GetBeanE("handlerChain")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got string
+	for _, handler := range bean.(*handlerChain).Handlers {
+		got += handler.Handle()
+	}
+	// 与 slice 集合注入相同，按 beanName 排序
+	if got != "abc" {
+		t.Fatalf("handlers %q, want %q", got, "abc")
+	}
+}
+
+func TestArrayCollectionCountMismatch(t *testing.T) {
+	ioc := newHandlerIOC(
+		NewClass("handlerA", (*handlerA)(nil), Singleton),
+		NewClass("handlerB", (*handlerB)(nil), Singleton),
+	)
+	if _, err := ioc.GetBeanE("handlerChain"); err == nil || !strings.Contains(err.Error(), "needs 3 beans, got 2") {
+		t.Fatalf("got %v, want array length mismatch error", err)
+	}
+}
+
+// namedHandlers 按 beanName 注入的集合 field，只有指定的 bean 不存在时才收集所有匹配的 bean
+type namedHandlers struct {
+	Registered map[string]Handler `di:"handlers"`
+	Chain      []Handler          `di:"chain"`
+	All        map[string]Handler `di:""`
+	Missing    []Handler          `di:"noSuchHandlers"`
+}
+
+func TestNamedCollectionBean(t *testing.T) {
+	custom := &handlerC{}
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("handlerA", (*handlerA)(nil), Singleton),
+		NewClass("handlerB", (*handlerB)(nil), Singleton),
+		NewClass("handlers", (*map[string]Handler)(nil), Singleton).SetSupplier(func() interface{} {
+			return &map[string]Handler{"custom": custom}
+		}),
+		NewClass("chain", (*[]Handler)(nil), Singleton).SetSupplier(func() interface{} {
+			return &[]Handler{custom}
+		}),
+		NewClass("namedHandlers", (*namedHandlers)(nil), Singleton),
+	)
+	bean, err := ioc.GetBeanE("namedHandlers")
+	if err != nil {
+		t.Fatal(err)
+	}
+	named := bean.(*namedHandlers)
+	if len(named.Registered) != 1 || named.Registered["custom"] != custom {
+		t.Fatalf("Registered = %v, want the registered handlers bean", named.Registered)
+	}
+	if len(named.Chain) != 1 || named.Chain[0] != custom {
+		t.Fatalf("Chain = %v, want the registered chain bean", named.Chain)
+	}
+	if len(named.All) != 2 || named.All["handlerA"] == nil || named.All["handlerB"] == nil {
+		t.Fatalf("All = %v, want every Handler bean", named.All)
+	}
+	if len(named.Missing) != 2 {
+		t.Fatalf("Missing = %v, want every Handler bean", named.Missing)
+	}
+	if got, want := ioc.Dependents("handlers"), []string{"namedHandlers"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dependents(handlers) = %v, want %v", got, want)
+	}
+}
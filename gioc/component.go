@@ -0,0 +1,71 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ComponentTag 类型级别的注册注解，标注在结构体的 _ field 上，格式为 gioc:"name,scope"，例如：
+//
+//	type UserService struct {
+//		_   struct{} `gioc:"userService,s"`
+//		Dao *UserDao `di:""`
+//	}
+//
+// name 为空时使用类型名称作为 beanName，scope 为空时注册为单例
+const ComponentTag = "gioc"
+
+// RegisterFromStructTags 按结构体的 gioc 注解批量注册 bean，vals 为结构体指针，例如 &UserService{}
+// 没有 gioc 注解的结构体使用类型名称作为 beanName 注册为单例，与按类型自动注册时的 beanName 保持一致
+// 某一项解析或者注册失败不影响其他项，所有失败以 MultiError 返回
+func (bc *BeanBeanFactory) RegisterFromStructTags(vals ...interface{}) error {
+	var errs MultiError
+	for _, v := range vals {
+		beanName, beanType, err := parseComponentTag(v)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := bc.Register(NewClass(beanName, v, beanType)); err != nil {
+			errs = append(errs, fmt.Errorf("bean %v: %w", beanName, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// parseComponentTag 解析结构体指针的 gioc 注解，返回 beanName 和作用域
+func parseComponentTag(v interface{}) (string, BeanType, error) {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return "", Invalid, fmt.Errorf("%T is not a pointer to struct", v)
+	}
+	t = t.Elem()
+	beanName, beanType := t.Name(), Singleton
+	for i := 0; i < t.NumField(); i++ {
+		tag, ok := t.Field(i).Tag.Lookup(ComponentTag)
+		if !ok {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		if len(parts) > 2 {
+			return "", Invalid, fmt.Errorf("%v: invalid %v tag %q, expected \"name,scope\"", t, ComponentTag, tag)
+		}
+		if name := strings.TrimSpace(parts[0]); name != "" {
+			beanName = name
+		}
+		if len(parts) == 2 {
+			if scope := strings.TrimSpace(parts[1]); scope != "" {
+				beanType = BeanType(scope)
+			}
+		}
+		break
+	}
+	if beanName == "" {
+		return "", Invalid, fmt.Errorf("%v: anonymous struct requires a name in %v tag", t, ComponentTag)
+	}
+	return beanName, beanType, nil
+}
@@ -0,0 +1,63 @@
+package gioc
+
+// Condition bean 的注册条件，设置了条件的 bean 在 Refresh 时才会对条件求值，所有条件都满足才会注册
+type Condition func(factory BeanFactory) bool
+
+// OnBeanPresent 名为 beanName 的 bean 已经注册时满足条件
+func OnBeanPresent(beanName string) Condition {
+	return func(factory BeanFactory) bool {
+		return len(factory.ListBeans(BeanFilter{Name: beanName})) > 0
+	}
+}
+
+// addConditionalClass 暂存设置了条件的 bean，等待 Refresh 时求值
+func (bc *BeanBeanFactory) addConditionalClass(class *Class) {
+	c := *class
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.conditionalClasses = append(bc.conditionalClasses, &c)
+}
+
+// registerConditionalClasses 按注册顺序对暂存的 bean 条件求值并注册满足条件的 bean
+// 一个 bean 注册后可能使其他 bean 的条件满足，因此重复求值直到没有新的 bean 注册，仍不满足条件的 bean 继续暂存
+func (bc *BeanBeanFactory) registerConditionalClasses() error {
+	for {
+		bc.mu.Lock()
+		pending := bc.conditionalClasses
+		bc.conditionalClasses = nil
+		bc.mu.Unlock()
+		var rest []*Class
+		registered := false
+		for i, class := range pending {
+			if !bc.matchConditions(class.conditions) {
+				rest = append(rest, class)
+				continue
+			}
+			c := *class
+			c.conditions = nil
+			if err := bc.Register(&c); err != nil {
+				bc.mu.Lock()
+				bc.conditionalClasses = append(append(rest, pending[i+1:]...), bc.conditionalClasses...)
+				bc.mu.Unlock()
+				return err
+			}
+			registered = true
+		}
+		bc.mu.Lock()
+		bc.conditionalClasses = append(rest, bc.conditionalClasses...)
+		bc.mu.Unlock()
+		if !registered {
+			return nil
+		}
+	}
+}
+
+// matchConditions 判断是否满足所有条件
+func (bc *BeanBeanFactory) matchConditions(conditions []Condition) bool {
+	for _, condition := range conditions {
+		if !condition(bc) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package gioc
+
+import "testing"
+
+type metricsRegistry struct {
+	x int
+}
+
+type metricsInterceptor struct {
+	Registry *metricsRegistry `di:"metricsRegistry"`
+}
+
+func registerInterceptor(t *testing.T, ioc *IOC) {
+	t.Helper()
+	class := NewClass("metricsInterceptor", (*metricsInterceptor)(nil), Singleton).SetConditions(OnBeanPresent("metricsRegistry"))
+	if err := ioc.Register(class); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOnBeanPresentAbsent(t *testing.T) {
+	ioc := NewIOC()
+	registerInterceptor(t, ioc)
+	if err := ioc.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ioc.LookupBean("metricsInterceptor"); ok {
+		t.Fatal("interceptor registered without the registry")
+	}
+}
+
+func TestOnBeanPresent(t *testing.T) {
+	ioc := NewIOC()
+	// 条件在 Refresh 时求值，先注册依赖方也可以
+	registerInterceptor(t, ioc)
+	if _, ok := ioc.LookupBean("metricsInterceptor"); ok {
+		t.Fatal("conditional bean registered before Refresh")
+	}
+	if err := ioc.Register(NewClass("metricsRegistry", (*metricsRegistry)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	bean, ok := ioc.LookupBean("metricsInterceptor")
+	if !ok || bean.(*metricsInterceptor).Registry != ioc.GetBean("metricsRegistry") {
+		t.Fatalf("got %v, %v, want interceptor wired to the registry", bean, ok)
+	}
+}
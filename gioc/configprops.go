@@ -0,0 +1,93 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationType time.Duration 的 reflect.Type
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// bindConfigProps 将属性源中前缀为 prefix 的属性绑定到结构体 field，属性名为 prefix.小写 field 名，例如 db.host
+// 嵌套的结构体以及结构体 ptr field 继续按 prefix.field 递归绑定，nil ptr 会先分配
+func (bc *BeanBeanFactory) bindConfigProps(fieldValue reflect.Value, field reflect.StructField, prefix string) {
+	v := fieldValue
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		panic(fmt.Errorf("field %v: %v tag requires a struct or struct pointer, got %v", field.Name, ConfigPropsTag, field.Type))
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		key := prefix + "." + strings.ToLower(sf.Name)
+		ft := sf.Type
+		if ft.Kind() == reflect.Struct || (ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct) {
+			bc.bindConfigProps(v.Field(i), sf, key)
+			continue
+		}
+		if bc.opts.propertySource == nil {
+			continue
+		}
+		value, exist := bc.opts.propertySource.Property(key)
+		if !exist {
+			continue
+		}
+		if err := setPropertyValue(v.Field(i), value); err != nil {
+			panic(fmt.Errorf("field %v: %v property %v: %v", field.Name, ConfigPropsTag, key, err))
+		}
+	}
+}
+
+// setPropertyValue 将字符串属性值转换为 field 的类型后赋值
+func setPropertyValue(fieldValue reflect.Value, value string) error {
+	if fieldValue.Type() == durationType {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(int64(d))
+		return nil
+	}
+	switch fieldValue.Kind() {
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		fieldValue.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, fieldValue.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fieldValue.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %v", fieldValue.Type())
+	}
+	return nil
+}
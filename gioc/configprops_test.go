@@ -0,0 +1,57 @@
+package gioc
+
+import (
+	"testing"
+	"time"
+)
+
+type poolConfig struct {
+	Size    int
+	Timeout time.Duration
+}
+
+type dbConfig struct {
+	Host string
+	Port int
+	Pool *poolConfig
+}
+
+// configHolder 持有不是 bean 的配置 ptr field
+type configHolder struct {
+	DB *dbConfig `configprops:"db"`
+}
+
+func TestConfigPropsNestedPointer(t *testing.T) {
+	ioc := NewIOC(WithPropertySource(MapPropertySource{
+		"db.host":         "localhost",
+		"db.port":         "5432",
+		"db.pool.size":    "8",
+		"db.pool.timeout": "3s",
+	}))
+	if err := ioc.Register(NewClass("configHolder", (*configHolder)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	holder := ioc.GetBean("configHolder").(*configHolder)
+	if holder.DB == nil || holder.DB.Pool == nil {
+		t.Fatalf("config pointers not allocated: %+v", holder.DB)
+	}
+	if holder.DB.Host != "localhost" || holder.DB.Port != 5432 {
+		t.Fatalf("db config = %+v", holder.DB)
+	}
+	if *holder.DB.Pool != (poolConfig{Size: 8, Timeout: 3 * time.Second}) {
+		t.Fatalf("pool config = %+v", holder.DB.Pool)
+	}
+	if ioc.GetRegisteredBeanCount() != 1 {
+		t.Fatal("config holder field should not be registered as a bean")
+	}
+}
+
+func TestConfigPropsInvalidValue(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false), WithPropertySource(MapPropertySource{"db.port": "not-a-port"}))
+	if err := ioc.Register(NewClass("configHolder", (*configHolder)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("configHolder"); err == nil {
+		t.Fatal("expected error for invalid property value")
+	}
+}
@@ -0,0 +1,233 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// BeanRefPrefix 构造函数参数中引用容器 bean 的前缀，例如 "@userDao"
+const BeanRefPrefix = "@"
+
+// errorType error 接口类型
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// checkConstructor 校验构造函数，返回构造函数创建的 bean 类型
+// t 为注册时指定的 bean 类型，为空时直接使用构造函数返回值类型
+func checkConstructor(constructor interface{}, t reflect.Type) (reflect.Type, error) {
+	ct := reflect.TypeOf(constructor)
+	if ct.Kind() != reflect.Func {
+		return nil, fmt.Errorf("constructor %v is not a func", ct)
+	}
+	// 返回值只能是 bean 或者 (bean, error)
+	if ct.NumOut() == 0 || ct.NumOut() > 2 || (ct.NumOut() == 2 && ct.Out(1) != errorType) {
+		return nil, fmt.Errorf("constructor %v must return bean or (bean, error)", ct)
+	}
+	if t == nil {
+		return ct.Out(0), nil
+	}
+	if !ct.Out(0).AssignableTo(t) {
+		return nil, fmt.Errorf("constructor %v returns %v, not assignable to %v", ct, ct.Out(0), t)
+	}
+	return t, nil
+}
+
+// callConstructor 解析构造函数参数并调用构造函数，返回创建的 bean value
+func (bc *BeanBeanFactory) callConstructor(ctx context.Context, constructor interface{}, args []interface{}) (reflect.Value, error) {
+	cv := reflect.ValueOf(constructor)
+	ct := cv.Type()
+	in, err := bc.resolveConstructorArgs(ctx, ct, args)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	// 构造函数返回 error 时按配置重试，参数只解析一次，重试间隔每次翻倍
+	backoff := bc.opts.retryBackoff
+	for attempt := 0; ; attempt++ {
+		out := cv.Call(in)
+		if len(out) < 2 || out[1].IsNil() {
+			return out[0], nil
+		}
+		err := out[1].Interface().(error)
+		if attempt >= bc.opts.retryAttempts {
+			if attempt > 0 {
+				return reflect.Value{}, fmt.Errorf("%v retries: %w", attempt, err)
+			}
+			return reflect.Value{}, err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// resolveConstructorArgs 解析构造函数的所有参数
+// 没有指定参数并且构造函数存在参数时，按参数类型从容器中自动获取 bean
+// context.Context 类型的参数总是传入创建 bean 的 ctx，指定参数时对应位置传 nil 即可
+func (bc *BeanBeanFactory) resolveConstructorArgs(ctx context.Context, ct reflect.Type, args []interface{}) ([]reflect.Value, error) {
+	if len(args) == 0 && ct.NumIn() > 0 && !ct.IsVariadic() {
+		in := make([]reflect.Value, ct.NumIn())
+		for i := range in {
+			if ct.In(i) == contextType {
+				in[i] = reflect.ValueOf(&ctx).Elem()
+				continue
+			}
+			argV, err := bc.autowireConstructorArg(ctx, ct.In(i))
+			if err != nil {
+				return nil, fmt.Errorf("constructor %v arg %v: %v", ct, i, err)
+			}
+			in[i] = argV
+		}
+		return in, nil
+	}
+	if err := checkConstructorArgs(ct, len(args)); err != nil {
+		return nil, err
+	}
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		if arg == nil && getConstructorParamType(ct, i) == contextType {
+			in[i] = reflect.ValueOf(&ctx).Elem()
+			continue
+		}
+		argV, err := bc.resolveConstructorArg(ctx, arg, getConstructorParamType(ct, i))
+		if err != nil {
+			return nil, fmt.Errorf("constructor %v arg %v: %v", ct, i, err)
+		}
+		in[i] = argV
+	}
+	return in, nil
+}
+
+// autowireConstructorArg 按类型从容器中获取构造函数参数
+// OptionalParam 参数以及 ptr 参数在容器中不存在对应 bean 时为零值，其他类型的参数必须存在对应的 bean
+func (bc *BeanBeanFactory) autowireConstructorArg(ctx context.Context, pt reflect.Type) (reflect.Value, error) {
+	if isOptionalParam(pt) {
+		et := reflect.New(pt).Interface().(optionalParam).elemType()
+		beanName, err := bc.getAutowireCandidate(et)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return bc.newOptionalParam(ctx, pt, beanName)
+	}
+	beanName, err := bc.getAutowireCandidate(pt)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	if beanName == "" {
+		if pt.Kind() == reflect.Ptr {
+			return reflect.Zero(pt), nil
+		}
+		return reflect.Value{}, fmt.Errorf("no bean assignable to %v", pt)
+	}
+	return bc.resolveConstructorArg(ctx, BeanRefPrefix+beanName, pt)
+}
+
+// getAutowireCandidate 获取唯一能够赋值给 t 的 beanName，存在多个时选择首选 bean，不存在时返回空
+func (bc *BeanBeanFactory) getAutowireCandidate(t reflect.Type) (string, error) {
+	beanNames := bc.getBeanNamesAssignableTo(t)
+	if len(beanNames) <= 1 {
+		return strings.Join(beanNames, ""), nil
+	}
+	var primary []string
+	for _, beanName := range beanNames {
+		if class := bc.getClass(beanName); class != nil && class.primary {
+			primary = append(primary, beanName)
+		}
+	}
+	if len(primary) != 1 {
+		return "", fmt.Errorf("more than one bean assignable to %v: %v", t, beanNames)
+	}
+	return primary[0], nil
+}
+
+// getEmbeddedCandidate 获取注入到嵌入接口 field 的 beanName，不存在时返回空
+// 嵌入了该接口的 bean 通过方法提升也实现了该接口，但它需要的正是被注入的实现，因此不作为候选
+func (bc *BeanBeanFactory) getEmbeddedCandidate(iface reflect.Type) (string, error) {
+	var beanNames, primary []string
+	bc.mu.RLock()
+	for beanName, t := range bc.tMap {
+		if !t.AssignableTo(iface) || embedsType(t, iface) {
+			continue
+		}
+		beanNames = append(beanNames, beanName)
+		if bc.cMap[beanName].primary {
+			primary = append(primary, beanName)
+		}
+	}
+	bc.sortBeanNames(beanNames)
+	bc.mu.RUnlock()
+	if len(beanNames) <= 1 {
+		return strings.Join(beanNames, ""), nil
+	}
+	if len(primary) != 1 {
+		return "", fmt.Errorf("more than one bean assignable to %v: %v", iface, beanNames)
+	}
+	return primary[0], nil
+}
+
+// embedsType 判断 t 或者 t 指向的结构体是否直接嵌入了 embedded 类型的 field
+func embedsType(t, embedded reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Anonymous && field.Type == embedded {
+			return true
+		}
+	}
+	return false
+}
+
+// checkConstructorArgs 校验构造函数参数个数
+func checkConstructorArgs(ct reflect.Type, n int) error {
+	if ct.IsVariadic() {
+		if n < ct.NumIn()-1 {
+			return fmt.Errorf("constructor %v needs at least %v args, got %v", ct, ct.NumIn()-1, n)
+		}
+	} else if n != ct.NumIn() {
+		return fmt.Errorf("constructor %v needs %v args, got %v", ct, ct.NumIn(), n)
+	}
+	return nil
+}
+
+// getConstructorParamType 获取构造函数第 i 个参数的类型，可变参数部分取切片的元素类型，越界返回 nil
+func getConstructorParamType(ct reflect.Type, i int) reflect.Type {
+	if ct.IsVariadic() && i >= ct.NumIn()-1 {
+		return ct.In(ct.NumIn() - 1).Elem()
+	}
+	if i >= ct.NumIn() {
+		return nil
+	}
+	return ct.In(i)
+}
+
+// resolveConstructorArg 解析单个构造函数参数，"@beanName" 格式的 string 参数替换为容器中的 bean
+func (bc *BeanBeanFactory) resolveConstructorArg(ctx context.Context, arg interface{}, pt reflect.Type) (reflect.Value, error) {
+	if arg == nil {
+		return reflect.Zero(pt), nil
+	}
+	// 可选参数，引用的 bean 不存在时为零值
+	if ref, ok := arg.(string); ok && strings.HasPrefix(ref, BeanRefPrefix) && isOptionalParam(pt) {
+		return bc.newOptionalParam(ctx, pt, strings.TrimPrefix(ref, BeanRefPrefix))
+	}
+	if ref, ok := arg.(string); ok && strings.HasPrefix(ref, BeanRefPrefix) && pt.Kind() != reflect.String {
+		beanName := strings.TrimPrefix(ref, BeanRefPrefix)
+		bean := bc.doGetBeanContext(dependencyContext(ctx), beanName, false)
+		if bean == nil {
+			return reflect.Value{}, fmt.Errorf("bean %v is not exist", beanName)
+		}
+		// 接口参数不传入类型化的 nil，保持真正的 nil 接口
+		if pt.Kind() == reflect.Interface && isNilBean(bean) {
+			return reflect.Zero(pt), nil
+		}
+		arg = bean
+	}
+	argV := reflect.ValueOf(arg)
+	if !argV.Type().AssignableTo(pt) {
+		return reflect.Value{}, fmt.Errorf("%v is not assignable to %v", argV.Type(), pt)
+	}
+	return argV, nil
+}
@@ -0,0 +1,104 @@
+package gioc
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type flakyConn struct {
+	attempts int
+}
+
+// newFlakyConstructor 返回前 failures 次调用失败的构造函数
+func newFlakyConstructor(failures int) (func() (*flakyConn, error), *int) {
+	calls := 0
+	return func() (*flakyConn, error) {
+		calls++
+		if calls <= failures {
+			return nil, errors.New("connection refused")
+		}
+		return &flakyConn{attempts: calls}, nil
+	}, &calls
+}
+
+func TestCreationRetry(t *testing.T) {
+	ioc := NewIOC(WithCreationRetry(3, time.Millisecond))
+	constructor, calls := newFlakyConstructor(2)
+	if err := ioc.Register(NewClass("conn", nil, Singleton).SetConstructor(constructor)); err != nil {
+		t.Fatal(err)
+	}
+	conn, err := ioc.GetBeanE("conn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conn.(*flakyConn).attempts != 3 || *calls != 3 {
+		t.Fatalf("constructor called %d times, want 3", *calls)
+	}
+}
+
+func TestCreationRetryExhausted(t *testing.T) {
+	ioc := NewIOC(WithCreationRetry(1, time.Millisecond), WithFailFast(false))
+	constructor, calls := newFlakyConstructor(2)
+	if err := ioc.Register(NewClass("conn", nil, Singleton).SetConstructor(constructor)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("conn"); err == nil {
+		t.Fatal("want error after retries are exhausted")
+	}
+	if *calls != 2 {
+		t.Fatalf("constructor called %d times, want 2", *calls)
+	}
+}
+
+type Logger interface {
+	Log(msg string)
+}
+
+type consoleLogger struct {
+	lines []string
+}
+
+func (l *consoleLogger) Log(msg string) {
+	l.lines = append(l.lines, msg)
+}
+
+type fileLogger struct {
+	consoleLogger
+}
+
+// loggedService 嵌入 Logger 接口，通过方法提升自身也实现了 Logger
+type loggedService struct {
+	Logger `di:""`
+}
+
+func TestEmbeddedInterfaceField(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("consoleLogger", (*consoleLogger)(nil), Singleton),
+		NewClass("loggedService", (*loggedService)(nil), Singleton),
+	)
+	service, err := ioc.GetBeanE("loggedService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger := service.(*loggedService).Logger; logger != ioc.GetBean("consoleLogger") {
+		t.Fatalf("embedded Logger = %#v, want consoleLogger", logger)
+	}
+}
+
+func TestEmbeddedInterfaceFieldPrimary(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("consoleLogger", (*consoleLogger)(nil), Singleton),
+		NewClass("fileLogger", (*fileLogger)(nil), Singleton).SetPrimary(true),
+		NewClass("loggedService", (*loggedService)(nil), Singleton),
+	)
+	service, err := ioc.GetBeanE("loggedService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logger := service.(*loggedService).Logger; logger != ioc.GetBean("fileLogger") {
+		t.Fatalf("embedded Logger = %#v, want primary fileLogger", logger)
+	}
+}
@@ -1,5 +1,7 @@
 package gioc
 
+import "context"
+
 // Container bean 容器接口
 type Container interface {
 	// Get 根据 beanName 获取 bean
@@ -21,24 +23,17 @@ func NewSingletonContainer(beanFactory BeanFactory) Container {
 
 // Get 获取 bean
 func (sc *SingletonContainer) Get(beanName string, new bool) interface{} {
-	var bean interface{}
-	if !new {
-		// 先从缓存中获取
-		bean = sc.getSingleton(beanName, sc.isAllowEarlyReference())
-		if bean != nil {
-			return bean
-		}
-	}
-	// 创建实例
-	bean = sc.createBean(beanName, Singleton, new)
-	if bean == nil {
-		return nil
-	}
-	if !new {
-		// 将 bean 添加到缓存中
-		sc.addSingleton(beanName, bean)
+	return sc.getContext(context.Background(), beanName, new)
+}
+
+// getContext 获取 bean，创建 bean 时使用 ctx
+func (sc *SingletonContainer) getContext(ctx context.Context, beanName string, new bool) interface{} {
+	// 全新的 bean 不经过单例缓存
+	if new {
+		return sc.createBean(ctx, beanName, Singleton, new)
 	}
-	return bean
+	// 从缓存中获取，不存在时创建并添加到缓存中，并发获取同一个单例时只会创建一次
+	return sc.getOrCreateSingleton(ctx, beanName)
 }
 
 // PrototypeContainer 原型 bean 容器
@@ -56,11 +51,71 @@ func NewPrototypeContainer(beanFactory BeanFactory) Container {
 
 // Get 获取 bean
 func (pc *PrototypeContainer) Get(beanName string, new bool) interface{} {
+	return pc.getContext(context.Background(), beanName, new)
+}
+
+// getContext 获取 bean，创建 bean 时使用 ctx
+func (pc *PrototypeContainer) getContext(ctx context.Context, beanName string, new bool) interface{} {
 	// 创建实例
-	bean := pc.createBean(beanName, Prototype, new)
+	bean := pc.createBean(ctx, beanName, Prototype, new)
 	if bean == nil {
 		return nil
 	}
 	// 原型 bean 不需要添加到缓存中
 	return bean
 }
+
+// contextContainer 创建 bean 时能够传递 context 的容器，内置容器都实现了该接口
+type contextContainer interface {
+	// getContext 根据 beanName 获取 bean，创建 bean 时使用 ctx
+	getContext(ctx context.Context, beanName string, new bool) interface{}
+}
+
+// Scope 自定义 bean 作用域，用户可以通过 RegisterScope 注册自己的作用域语义
+type Scope interface {
+	// Get 根据 beanName 获取 bean，objectFactory 用于在作用域内不存在 bean 时创建新的 bean
+	Get(beanName string, objectFactory func() interface{}) interface{}
+}
+
+// ContextScope 绑定 context 的作用域，作用域内的 bean 可以通过 scopectx 注解注入该 context
+type ContextScope interface {
+	Scope
+	// Context 返回当前作用域的 context
+	Context() context.Context
+}
+
+// ScopeContainer 自定义作用域 bean 容器，bean 的缓存策略交由 Scope 实现
+type ScopeContainer struct {
+	// 维护 beanFactory
+	BeanFactory
+	// 作用域名称
+	beanType BeanType
+	// 用户注册的作用域
+	scope Scope
+}
+
+// NewScopeContainer 实例化一个自定义作用域 bean 容器
+func NewScopeContainer(beanFactory BeanFactory, beanType BeanType, scope Scope) Container {
+	return &ScopeContainer{
+		BeanFactory: beanFactory,
+		beanType:    beanType,
+		scope:       scope,
+	}
+}
+
+// Get 获取 bean
+func (sc *ScopeContainer) Get(beanName string, new bool) interface{} {
+	return sc.getContext(context.Background(), beanName, new)
+}
+
+// getContext 获取 bean，作用域内不存在 bean 时使用 ctx 创建
+func (sc *ScopeContainer) getContext(ctx context.Context, beanName string, new bool) interface{} {
+	objectFactory := func() interface{} {
+		return sc.createBean(ctx, beanName, sc.beanType, new)
+	}
+	// 获取全新的 bean，不经过作用域缓存
+	if new {
+		return objectFactory()
+	}
+	return sc.scope.Get(beanName, objectFactory)
+}
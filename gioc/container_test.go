@@ -0,0 +1,60 @@
+package gioc
+
+import (
+	"strings"
+	"testing"
+)
+
+// counterScope 每 n 次获取才创建一个新的 bean，其余获取返回上一次创建的 bean
+type counterScope struct {
+	n     int
+	calls int
+	beans map[string]interface{}
+}
+
+func (s *counterScope) Get(beanName string, objectFactory func() interface{}) interface{} {
+	if s.calls%s.n == 0 {
+		s.beans[beanName] = objectFactory()
+	}
+	s.calls++
+	return s.beans[beanName]
+}
+
+type counted struct {
+	x int
+}
+
+func TestCustomScope(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.RegisterScope("counter", &counterScope{n: 3, beans: map[string]interface{}{}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("counted", (*counted)(nil), "counter")); err != nil {
+		t.Fatal(err)
+	}
+	var beans []interface{}
+	for i := 0; i < 7; i++ {
+		beans = append(beans, ioc.GetBean("counted"))
+	}
+	for i, bean := range beans {
+		// 第 0、3、6 次获取创建新的 bean，其余与同组第一次相同
+		first := beans[i/3*3]
+		if bean != first {
+			t.Fatalf("call %d got %p, want %p", i, bean, first)
+		}
+		if i%3 == 0 && i > 0 && bean == beans[i-1] {
+			t.Fatalf("call %d reused %p, want a new instance", i, bean)
+		}
+	}
+}
+
+func TestRegisterUnknownScope(t *testing.T) {
+	ioc := NewIOC()
+	err := ioc.Register(NewClass("counted", (*counted)(nil), "counter"))
+	if err == nil || !strings.Contains(err.Error(), "counter") {
+		t.Fatalf("got %v, want error naming the unknown scope", err)
+	}
+	if err := ioc.RegisterScope("s", &counterScope{n: 1}); err == nil {
+		t.Fatal("want error overriding the built-in singleton scope")
+	}
+}
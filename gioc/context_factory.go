@@ -0,0 +1,33 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+)
+
+// ContextualBeanFactory 支持 context 的 bean 工厂，ctx 会传递给 bean 的创建过程
+type ContextualBeanFactory interface {
+	// GetBeanContext 根据 beanName 获取 bean，创建 bean 时将 ctx 传递给构造函数的 context.Context 参数以及 InitializingBean.AfterPropertiesSet
+	GetBeanContext(ctx context.Context, beanName string) (interface{}, error)
+}
+
+var _ ContextualBeanFactory = (*BeanBeanFactory)(nil)
+
+// GetBeanContext 根据 beanName 获取 bean 实例，ctx 已经取消时直接返回 ctx 的错误
+// 只有本次调用实际创建的 bean 能够收到 ctx，已经缓存的单例 bean 直接返回；
+// 属性注入时级联创建的依赖 bean 使用 context.Background()
+func (bc *BeanBeanFactory) GetBeanContext(ctx context.Context, beanName string) (bean interface{}, err error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("bean %v: %w", beanName, err)
+	}
+	if bc.getBeanType(beanName) == Invalid {
+		return nil, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if err := bc.acquireCall(beanName); err != nil {
+		return nil, err
+	}
+	defer bc.releaseCall()
+	defer bc.recoverCreatePanic(&err)
+	bean = bc.doGetBeanContext(ctx, beanName, false)
+	return bean, nil
+}
@@ -0,0 +1,115 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+)
+
+// creationChain 一次获取 bean 触发的创建链，链上级联创建的所有 bean 共享同一个 creationChain
+// 创建链在调用方的 goroutine 中同步执行，除 waitingFor 外的字段只会被该 goroutine 访问
+type creationChain struct {
+	// 当前嵌套创建的深度
+	depth int
+	// 正在等待的其他创建链，由 smu 保护，用于发现两条创建链互相等待
+	waitingFor *creationChain
+}
+
+// singletonCreation 正在创建的单例 bean
+type singletonCreation struct {
+	// 负责创建的创建链
+	chain *creationChain
+	// 创建结束（成功或者失败）时关闭
+	done chan struct{}
+}
+
+// creationChainKey creationChain 在 context 中的 key
+type creationChainKey struct{}
+
+// getCreationChain 获取 ctx 所属的创建链，ctx 不属于任何创建链时开启一条新的创建链
+func getCreationChain(ctx context.Context) (context.Context, *creationChain) {
+	if chain, ok := ctx.Value(creationChainKey{}).(*creationChain); ok {
+		return ctx, chain
+	}
+	chain := &creationChain{}
+	return context.WithValue(ctx, creationChainKey{}, chain), chain
+}
+
+// dependencyContext 获取依赖 bean 使用的 context，只保留 ctx 所属的创建链，不传递调用方的取消以及其他值
+func dependencyContext(ctx context.Context) context.Context {
+	if chain, ok := ctx.Value(creationChainKey{}).(*creationChain); ok {
+		return context.WithValue(context.Background(), creationChainKey{}, chain)
+	}
+	return context.Background()
+}
+
+// getOrCreateSingleton 获取单例 bean，不存在时创建并放入单例池，同一个单例同一时间只会被一条创建链创建
+// 其他创建链正在创建时等待其完成，创建失败时由等待者重新创建；
+// 同一条创建链再次获取正在创建的单例说明存在循环依赖，允许早期对象时返回早期对象，否则 panic
+func (bc *BeanBeanFactory) getOrCreateSingleton(ctx context.Context, beanName string) interface{} {
+	ctx, chain := getCreationChain(ctx)
+	var creation *singletonCreation
+	for creation == nil {
+		bc.smu.Lock()
+		if bean := bc.singletonMap[beanName]; bean != nil {
+			bc.smu.Unlock()
+			return bean
+		}
+		current := bc.creatingMap[beanName]
+		if current == nil {
+			creation = &singletonCreation{chain: chain, done: make(chan struct{})}
+			bc.creatingMap[beanName] = creation
+			bc.smu.Unlock()
+			break
+		}
+		// 同一条创建链再次获取，或者两条创建链互相等待，都是循环依赖，只能通过早期对象解决
+		if current.chain == chain || current.chain.waitsFor(chain) {
+			bc.smu.Unlock()
+			return bc.getEarlySingleton(beanName)
+		}
+		chain.waitingFor = current.chain
+		bc.smu.Unlock()
+		<-current.done
+		bc.smu.Lock()
+		chain.waitingFor = nil
+		bc.smu.Unlock()
+	}
+	created := false
+	defer func() {
+		bc.smu.Lock()
+		delete(bc.creatingMap, beanName)
+		// 创建失败，丢弃残留的早期对象，等待者会重新创建
+		if !created {
+			delete(bc.earlyMap, beanName)
+			delete(bc.factoryMap, beanName)
+		}
+		bc.smu.Unlock()
+		close(creation.done)
+	}()
+	bean := bc.createBean(ctx, beanName, Singleton, false)
+	if bean == nil {
+		return nil
+	}
+	bc.addSingleton(beanName, bean)
+	created = true
+	return bean
+}
+
+// getEarlySingleton 获取正在创建的单例 bean 的早期对象，不允许早期对象或者还没有暴露早期对象时 panic
+func (bc *BeanBeanFactory) getEarlySingleton(beanName string) interface{} {
+	if bc.isAllowEarlyReference() {
+		if bean := bc.getSingleton(beanName, true); bean != nil {
+			return bean
+		}
+	}
+	panic(fmt.Errorf("bean %v is creating, circular dependency can not be resolved, mark an interface field in the cycle with di:\",lazy\"", beanName))
+}
+
+// waitsFor 判断 c 是否直接或者间接地在等待 other，调用方需要持有 smu
+func (c *creationChain) waitsFor(other *creationChain) bool {
+	for waiting := c; waiting != nil; waiting = waiting.waitingFor {
+		if waiting == other {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package gioc
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+type slowSingleton struct {
+	Dep *slowDependency `di:"slowDependency"`
+}
+
+func (*slowSingleton) AfterPropertiesSet(ctx context.Context) error {
+	time.Sleep(20 * time.Millisecond)
+	return nil
+}
+
+type slowDependency struct{}
+
+func TestConcurrentSingletonCreatedOnce(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("x", (*slowSingleton)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	beans := make([]interface{}, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			beans[i], errs[i] = ioc.GetBeanE("x")
+		}(i)
+	}
+	wg.Wait()
+	for i := 0; i < n; i++ {
+		if errs[i] != nil {
+			t.Fatalf("call %v: %v", i, errs[i])
+		}
+		if beans[i] == nil || beans[i] != beans[0] {
+			t.Fatalf("call %v got %p, want %p", i, beans[i], beans[0])
+		}
+	}
+}
+
+type crossA struct {
+	B *crossB `di:"crossB"`
+}
+
+func (*crossA) AfterPropertiesSet(ctx context.Context) error {
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+type crossB struct {
+	A *crossA `di:"crossA"`
+}
+
+func (*crossB) AfterPropertiesSet(ctx context.Context) error {
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestConcurrentCircularDependencyDoesNotDeadlock(t *testing.T) {
+	ioc := NewIOC(WithAllowEarlyReference(true))
+	ioc.Register(NewClass("crossA", (*crossA)(nil), Singleton))
+	ioc.Register(NewClass("crossB", (*crossB)(nil), Singleton))
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		var wg sync.WaitGroup
+		for _, name := range []string{"crossA", "crossB"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				if _, err := ioc.GetBeanE(name); err != nil {
+					t.Error(err)
+				}
+			}(name)
+		}
+		wg.Wait()
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("deadlock")
+	}
+	a, b := ioc.GetBean("crossA").(*crossA), ioc.GetBean("crossB").(*crossB)
+	if a.B != b || b.A != a {
+		t.Fatal("circular references are not the singleton instances")
+	}
+}
@@ -0,0 +1,41 @@
+package gioc
+
+import "fmt"
+
+// Decorator bean 装饰器，接收原始 bean，返回包装后的 bean
+type Decorator func(orig interface{}) interface{}
+
+// Decorate 为 bean 注册装饰器，bean 初始化时在 bean 处理器之后按注册顺序应用，缓存以及注入的都是装饰后的 bean
+// 例如为 Repository 包装一层缓存，依赖方无需修改；装饰后的类型与原始类型不同时，依赖方需要以接口注入
+// bean 必须已经注册，已经创建的单例 bean 不能再装饰
+func (bc *BeanBeanFactory) Decorate(beanName string, decorator Decorator) error {
+	if decorator == nil {
+		return fmt.Errorf("decorator of bean %v is nil", beanName)
+	}
+	beanType := bc.getBeanType(beanName)
+	if beanType == Invalid {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if isSingleton(beanType) && bc.isSingletonCreated(beanName) {
+		return fmt.Errorf("bean %v has been created and can not be decorated", beanName)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.decoratorMap[beanName] = append(bc.decoratorMap[beanName], decorator)
+	return nil
+}
+
+// applyDecorators 按注册顺序对 bean 应用装饰器，装饰器返回 nil 时 panic
+func (bc *BeanBeanFactory) applyDecorators(beanName string, bean interface{}) interface{} {
+	bc.mu.RLock()
+	decorators := bc.decoratorMap[beanName]
+	bc.mu.RUnlock()
+	for _, decorator := range decorators {
+		decorated := decorator(bean)
+		if decorated == nil {
+			panic(fmt.Errorf("decorator of bean %v returns nil", beanName))
+		}
+		bean = decorated
+	}
+	return bean
+}
@@ -0,0 +1,57 @@
+package gioc
+
+import "testing"
+
+type Repository interface {
+	Find() string
+}
+
+type dbRepository struct {
+	x int
+}
+
+func (*dbRepository) Find() string { return "db" }
+
+// wrappingRepository 在原始 Repository 外包装一层
+type wrappingRepository struct {
+	name string
+	next Repository
+}
+
+func (r *wrappingRepository) Find() string { return r.name + "(" + r.next.Find() + ")" }
+
+type repositoryUser struct {
+	Repo Repository `di:"repository"`
+}
+
+func wrapWith(name string) Decorator {
+	return func(orig interface{}) interface{} {
+		return &wrappingRepository{name: name, next: orig.(Repository)}
+	}
+}
+
+func TestDecorate(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("repository", (*dbRepository)(nil), Singleton),
+		NewClass("repositoryUser", (*repositoryUser)(nil), Singleton),
+	)
+	if err := ioc.Decorate("repository", wrapWith("cache")); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Decorate("repository", wrapWith("metrics")); err != nil {
+		t.Fatal(err)
+	}
+	user := ioc.GetBean("repositoryUser").(*repositoryUser)
+	// 按注册顺序应用，后注册的装饰器在最外层
+	if got := user.Repo.Find(); got != "metrics(cache(db))" {
+		t.Fatalf("got %v, want metrics(cache(db))", got)
+	}
+	// 缓存的也是装饰后的 bean
+	if ioc.GetBean("repository") != user.Repo {
+		t.Fatal("cached bean differs from the injected decorator")
+	}
+	if err := ioc.Decorate("repository", wrapWith("late")); err == nil {
+		t.Fatal("want error decorating a created singleton")
+	}
+}
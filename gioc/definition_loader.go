@@ -0,0 +1,140 @@
+package gioc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DefinitionFormatJSON json 格式的 bean 描述文件
+const DefinitionFormatJSON = "json"
+
+// beanDescriptor bean 描述文件中的一个 bean 定义
+type beanDescriptor struct {
+	Name            string                     `json:"name"`
+	Type            string                     `json:"type"`
+	Scope           BeanType                   `json:"scope"`
+	Qualifier       string                     `json:"qualifier"`
+	QualifierAttr   map[string]string          `json:"qualifierAttributes"`
+	Primary         bool                       `json:"primary"`
+	InitMethod      string                     `json:"initMethod"`
+	DestroyMethod   string                     `json:"destroyMethod"`
+	DestroyPriority int                        `json:"destroyPriority"`
+	Properties      map[string]json.RawMessage `json:"properties"`
+}
+
+// RegisterType 注册可以在 bean 描述文件中通过名称引用的类型，i 与 NewClass 的 i 相同，例如 (*A)(nil)
+// Go 无法通过类型名称实例化任意类型，因此描述文件中引用的类型必须预先注册
+func (bc *BeanBeanFactory) RegisterType(name string, i interface{}) error {
+	t, ok := i.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(i)
+	}
+	if name == "" || t == nil {
+		return fmt.Errorf("type %v: name and type are required", name)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if _, exist := bc.typeRegistry[name]; exist {
+		return fmt.Errorf("type %v was registered", name)
+	}
+	bc.typeRegistry[name] = t
+	return nil
+}
+
+// LoadDefinitions 从描述文件加载 bean 定义并注册，支持 json 以及 yaml 格式，内容为 bean 定义数组，例如：
+// [{"name": "a", "type": "A", "scope": "s", "primary": true, "properties": {"Port": 8080}}]
+// yaml 格式的字段与 json 相同，每个 bean 定义为块序列中的一个映射，properties 为嵌套的映射
+// scope 为空时注册为单例，properties 按 field 类型解析，任意一个 bean 注册失败时已经注册的 bean 会被注销
+func (bc *BeanBeanFactory) LoadDefinitions(r io.Reader, format string) error {
+	descriptors, err := decodeDescriptors(r, format)
+	if err != nil {
+		return err
+	}
+	classes := make([]*Class, 0, len(descriptors))
+	for _, d := range descriptors {
+		class, err := bc.descriptorToClass(d)
+		if err != nil {
+			return err
+		}
+		classes = append(classes, class)
+	}
+	for i, class := range classes {
+		if err := bc.Register(class); err != nil {
+			for _, registered := range classes[:i] {
+				bc.unregister(registered.beanName)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// decodeDescriptors 按 format 解码描述文件中的 bean 定义，yaml 先解析为通用结构再按 json 解码，两种格式的字段完全一致
+func decodeDescriptors(r io.Reader, format string) ([]beanDescriptor, error) {
+	var descriptors []beanDescriptor
+	switch format {
+	case DefinitionFormatJSON:
+		if err := json.NewDecoder(r).Decode(&descriptors); err != nil {
+			return nil, fmt.Errorf("decode definitions: %v", err)
+		}
+	case DefinitionFormatYAML:
+		value, err := decodeYAML(r)
+		if err != nil {
+			return nil, fmt.Errorf("decode definitions: %v", err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("decode definitions: %v", err)
+		}
+		if err := json.Unmarshal(data, &descriptors); err != nil {
+			return nil, fmt.Errorf("decode definitions: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("definition format %v is not supported", format)
+	}
+	return descriptors, nil
+}
+
+// descriptorToClass 将描述文件中的 bean 定义转换为 Class
+func (bc *BeanBeanFactory) descriptorToClass(d beanDescriptor) (*Class, error) {
+	bc.mu.RLock()
+	t, exist := bc.typeRegistry[d.Type]
+	bc.mu.RUnlock()
+	if !exist {
+		return nil, fmt.Errorf("bean %v: type %v is not registered", d.Name, d.Type)
+	}
+	scope := d.Scope
+	if scope == Invalid {
+		scope = Singleton
+	}
+	class := NewClass(d.Name, t, scope).
+		SetQualifier(d.Qualifier).
+		SetPrimary(d.Primary).
+		SetInitMethod(d.InitMethod).
+		SetDestroyMethod(d.DestroyMethod).
+		SetDestroyPriority(d.DestroyPriority)
+	for key, value := range d.QualifierAttr {
+		class.SetQualifierAttribute(key, value)
+	}
+	st := t
+	if st.Kind() == reflect.Ptr {
+		st = st.Elem()
+	}
+	for name, raw := range d.Properties {
+		if st.Kind() != reflect.Struct {
+			return nil, fmt.Errorf("bean %v: property %v: %v is not a struct", d.Name, name, t)
+		}
+		field, exist := st.FieldByName(name)
+		if !exist {
+			return nil, fmt.Errorf("bean %v: property %v: no field on %v", d.Name, name, t)
+		}
+		value := reflect.New(field.Type)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			return nil, fmt.Errorf("bean %v: property %v: %v", d.Name, name, err)
+		}
+		class.SetProperty(name, value.Elem().Interface())
+	}
+	return class, nil
+}
@@ -0,0 +1,86 @@
+package gioc
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type loadedServer struct {
+	Host  string
+	Port  int
+	Tags  []string
+	Store *loadedStore `di:"store"`
+}
+
+type loadedStore struct {
+	Limits map[string]int
+}
+
+const definitionsYAML = `
+# 两个 bean 定义
+- name: server
+  type: Server
+  scope: p
+  properties:
+    Host: "localhost" # 行尾注释
+    Port: 8080
+    Tags: [a, 'b, c']
+- name: store
+  type: Store
+  primary: true
+  properties:
+    Limits:
+      read: 10
+      write: 5
+`
+
+func TestLoadDefinitionsYAML(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.RegisterType("Server", (*loadedServer)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.RegisterType("Store", (*loadedStore)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.LoadDefinitions(strings.NewReader(definitionsYAML), DefinitionFormatYAML); err != nil {
+		t.Fatal(err)
+	}
+	scopes := map[string]BeanType{}
+	for _, info := range ioc.ListBeans(BeanFilter{}) {
+		scopes[info.Name] = info.Scope
+	}
+	// 没有声明 scope 的 bean 注册为单例
+	if want := map[string]BeanType{"server": Prototype, "store": Singleton}; !reflect.DeepEqual(scopes, want) {
+		t.Fatalf("got scopes %v, want %v", scopes, want)
+	}
+	server := ioc.GetBean("server").(*loadedServer)
+	if server.Host != "localhost" || server.Port != 8080 || !reflect.DeepEqual(server.Tags, []string{"a", "b, c"}) {
+		t.Fatalf("got properties %+v", server)
+	}
+	if server.Store == nil || !reflect.DeepEqual(server.Store.Limits, map[string]int{"read": 10, "write": 5}) {
+		t.Fatalf("got store %+v", server.Store)
+	}
+	if ioc.GetBean("server") == server {
+		t.Fatal("prototype bean returned the same instance")
+	}
+}
+
+func TestLoadDefinitionsYAMLInvalid(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.RegisterType("Store", (*loadedStore)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	for _, doc := range []string{
+		"- name: store\n  type: Store\n    scope: s\n",
+		"- name: store\n  name: again\n",
+		"- name: store\n\ttype: Store\n",
+	} {
+		if err := ioc.LoadDefinitions(strings.NewReader(doc), DefinitionFormatYAML); err == nil {
+			t.Fatalf("want error for %q", doc)
+		}
+	}
+	if n := ioc.GetRegisteredBeanCount(); n != 0 {
+		t.Fatalf("registered %d beans from invalid definitions", n)
+	}
+}
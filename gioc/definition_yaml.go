@@ -0,0 +1,304 @@
+package gioc
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DefinitionFormatYAML yaml 格式的 bean 描述文件
+const DefinitionFormatYAML = "yaml"
+
+// yamlLine yaml 文件中去掉注释后的非空行
+type yamlLine struct {
+	// 行号，从 1 开始，用于错误信息
+	no int
+	// 缩进的空格数
+	indent int
+	// 去掉缩进以及注释后的内容
+	text string
+}
+
+// yamlParser 解析 bean 描述文件使用的 yaml 子集：块映射、块序列、流式序列和映射以及标量
+// 不支持锚点、标签、多文档以及多行字符串，标量按 yaml 1.2 core schema 解析
+type yamlParser struct {
+	lines []yamlLine
+	pos   int
+}
+
+// decodeYAML 将 yaml 解析为与 json 解码结果相同的结构，映射为 map[string]interface{}，序列为 []interface{}
+func decodeYAML(r io.Reader) (interface{}, error) {
+	var lines []yamlLine
+	scanner := bufio.NewScanner(r)
+	for no := 1; scanner.Scan(); no++ {
+		raw := scanner.Text()
+		text := strings.TrimLeft(raw, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %v: tab indentation is not allowed", no)
+		}
+		text = strings.TrimRight(stripYAMLComment(text), " \t")
+		if text == "" || text == "---" {
+			continue
+		}
+		lines = append(lines, yamlLine{no: no, indent: len(raw) - len(strings.TrimLeft(raw, " ")), text: text})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, nil
+	}
+	p := &yamlParser{lines: lines}
+	value, err := p.parseNode(lines[0].indent)
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.lines) {
+		return nil, fmt.Errorf("line %v: unexpected indentation", p.lines[p.pos].no)
+	}
+	return value, nil
+}
+
+// parseNode 解析从当前行开始、缩进为 indent 的块序列或者块映射
+func (p *yamlParser) parseNode(indent int) (interface{}, error) {
+	if isYAMLSequenceItem(p.lines[p.pos].text) {
+		return p.parseSequence(indent)
+	}
+	return p.parseMapping(indent)
+}
+
+// parseSequence 解析缩进为 indent 的块序列
+func (p *yamlParser) parseSequence(indent int) (interface{}, error) {
+	items := []interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && isYAMLSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		rest := strings.TrimLeft(line.text[1:], " ")
+		switch {
+		case rest == "":
+			// 序列项的内容在下一行
+			p.pos++
+			item, err := p.parseChild(line, indent, false)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		case isYAMLMappingEntry(rest):
+			// "- key: value" 开始的映射，后续的键与 key 对齐
+			p.lines[p.pos] = yamlLine{no: line.no, indent: indent + len(line.text) - len(rest), text: rest}
+			item, err := p.parseMapping(p.lines[p.pos].indent)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		default:
+			item, err := parseYAMLScalar(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %v: %v", line.no, err)
+			}
+			items = append(items, item)
+			p.pos++
+		}
+	}
+	return items, nil
+}
+
+// parseMapping 解析缩进为 indent 的块映射
+func (p *yamlParser) parseMapping(indent int) (interface{}, error) {
+	m := map[string]interface{}{}
+	for p.pos < len(p.lines) && p.lines[p.pos].indent == indent && !isYAMLSequenceItem(p.lines[p.pos].text) {
+		line := p.lines[p.pos]
+		key, rest, err := splitYAMLMappingEntry(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %v: %v", line.no, err)
+		}
+		if _, exist := m[key]; exist {
+			return nil, fmt.Errorf("line %v: duplicate key %v", line.no, key)
+		}
+		p.pos++
+		if rest == "" {
+			// 值在下一行，映射的值为序列时序列项可以与键对齐
+			if m[key], err = p.parseChild(line, indent, true); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if m[key], err = parseYAMLScalar(rest); err != nil {
+			return nil, fmt.Errorf("line %v: %v", line.no, err)
+		}
+	}
+	if p.pos < len(p.lines) && p.lines[p.pos].indent > indent {
+		return nil, fmt.Errorf("line %v: unexpected indentation", p.lines[p.pos].no)
+	}
+	return m, nil
+}
+
+// parseChild 解析 parent 行的子节点，子节点的缩进必须大于 parent，allowAlignedSequence 时允许与 parent 对齐的块序列
+// 不存在子节点时返回 nil
+func (p *yamlParser) parseChild(parent yamlLine, indent int, allowAlignedSequence bool) (interface{}, error) {
+	if p.pos >= len(p.lines) {
+		return nil, nil
+	}
+	next := p.lines[p.pos]
+	if next.indent > indent || (allowAlignedSequence && next.indent == indent && isYAMLSequenceItem(next.text)) {
+		return p.parseNode(next.indent)
+	}
+	return nil, nil
+}
+
+// isYAMLSequenceItem 判断是否是块序列项
+func isYAMLSequenceItem(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+// isYAMLMappingEntry 判断是否是 "key: value" 形式的映射项
+func isYAMLMappingEntry(text string) bool {
+	if strings.HasPrefix(text, "[") || strings.HasPrefix(text, "{") {
+		return false
+	}
+	_, _, err := splitYAMLMappingEntry(text)
+	return err == nil
+}
+
+// splitYAMLMappingEntry 将 "key: value" 拆分为 key 和 value，key 可以使用引号
+func splitYAMLMappingEntry(text string) (string, string, error) {
+	if strings.HasPrefix(text, `"`) || strings.HasPrefix(text, "'") {
+		end := closingQuote(text)
+		if end < 0 || !strings.HasPrefix(text[end+1:], ":") {
+			return "", "", fmt.Errorf("invalid mapping entry %q", text)
+		}
+		key, err := parseYAMLScalar(text[:end+1])
+		if err != nil {
+			return "", "", err
+		}
+		return key.(string), strings.TrimLeft(text[end+2:], " "), nil
+	}
+	for i := 0; i < len(text); i++ {
+		if text[i] == ':' && (i == len(text)-1 || text[i+1] == ' ') {
+			return strings.TrimRight(text[:i], " "), strings.TrimLeft(text[i+1:], " "), nil
+		}
+	}
+	return "", "", fmt.Errorf("invalid mapping entry %q", text)
+}
+
+// parseYAMLScalar 解析标量以及流式序列和映射
+func parseYAMLScalar(s string) (interface{}, error) {
+	switch {
+	case s == "" || s == "~" || s == "null" || s == "Null" || s == "NULL":
+		return nil, nil
+	case s == "true" || s == "True" || s == "TRUE":
+		return true, nil
+	case s == "false" || s == "False" || s == "FALSE":
+		return false, nil
+	case strings.HasPrefix(s, `"`):
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("invalid quoted string %v", s)
+		}
+		return strconv.Unquote(s)
+	case strings.HasPrefix(s, "'"):
+		if closingQuote(s) != len(s)-1 {
+			return nil, fmt.Errorf("invalid quoted string %v", s)
+		}
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'"), nil
+	case strings.HasPrefix(s, "["):
+		if !strings.HasSuffix(s, "]") {
+			return nil, fmt.Errorf("unterminated flow sequence %v", s)
+		}
+		items := []interface{}{}
+		for _, part := range splitYAMLFlow(s[1 : len(s)-1]) {
+			item, err := parseYAMLScalar(part)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case strings.HasPrefix(s, "{"):
+		if !strings.HasSuffix(s, "}") {
+			return nil, fmt.Errorf("unterminated flow mapping %v", s)
+		}
+		m := map[string]interface{}{}
+		for _, part := range splitYAMLFlow(s[1 : len(s)-1]) {
+			key, rest, err := splitYAMLMappingEntry(part)
+			if err != nil {
+				return nil, err
+			}
+			if m[key], err = parseYAMLScalar(rest); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	}
+	// 数字保持原样，按 field 类型解析，避免大整数转换为 float64 丢失精度
+	if c := s[0]; (c == '-' || c >= '0' && c <= '9') && json.Valid([]byte(s)) {
+		return json.Number(s), nil
+	}
+	return s, nil
+}
+
+// splitYAMLFlow 按顶层的逗号拆分流式序列或者映射的内容，忽略引号以及嵌套括号中的逗号
+func splitYAMLFlow(s string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"', '\'':
+			if end := closingQuote(s[i:]); end > 0 {
+				i += end
+			}
+		case '[', '{':
+			depth++
+		case ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" || len(parts) > 0 {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// closingQuote 返回 s 开头的引号字符串的结束引号位置，不存在时返回 -1
+func closingQuote(s string) int {
+	quote := s[0]
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			// 单引号字符串中 '' 表示一个单引号
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+// stripYAMLComment 去掉行尾注释，# 位于行首或者空白之后并且不在引号中时才是注释
+func stripYAMLComment(text string) string {
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '"', '\'':
+			if i > 0 && text[i-1] != ' ' && text[i-1] != '[' && text[i-1] != '{' && text[i-1] != ',' {
+				// 普通标量中间的引号
+				continue
+			}
+			if end := closingQuote(text[i:]); end > 0 {
+				i += end
+			}
+		case '#':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '\t' {
+				return text[:i]
+			}
+		}
+	}
+	return text
+}
@@ -0,0 +1,293 @@
+package gioc
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// dependency bean 的一个静态依赖，从注册的类型信息中解析得到
+type dependency struct {
+	// 依赖来源，field 名称或者构造函数参数下标
+	source string
+	// 依赖的 beanName
+	beanName string
+	// 注入点需要的类型
+	t reflect.Type
+	// 是否是构造函数参数
+	isArg bool
+	// 是否延迟注入，延迟注入不会构成创建时的循环依赖
+	lazy bool
+	// 是否可选，可选依赖的 bean 不存在时注入零值
+	optional bool
+}
+
+// getDependencies 静态解析 bean 的依赖，只读取注册信息，不会创建或注册 bean
+// 无法解析的依赖以 error 返回
+func (bc *BeanBeanFactory) getDependencies(beanName string) ([]dependency, []error) {
+	bc.mu.RLock()
+	tPtr := bc.tMap[beanName]
+	class := bc.cMap[beanName]
+	bc.mu.RUnlock()
+	if tPtr == nil {
+		return nil, []error{fmt.Errorf("bean %v is not registered", beanName)}
+	}
+	var deps []dependency
+	var errs []error
+	// 构造函数参数依赖
+	if class.constructor != nil {
+		ct := reflect.TypeOf(class.constructor)
+		for i, arg := range class.constructorArgs {
+			pt := getConstructorParamType(ct, i)
+			ref, ok := arg.(string)
+			if pt == nil || !ok || !strings.HasPrefix(ref, BeanRefPrefix) || pt.Kind() == reflect.String {
+				continue
+			}
+			dep := dependency{
+				source:   "arg" + strconv.Itoa(i),
+				beanName: bc.canonicalName(strings.TrimPrefix(ref, BeanRefPrefix)),
+				t:        pt,
+				isArg:    true,
+			}
+			// 可选参数需要的是 OptionalParam 中的 bean 类型
+			if isOptionalParam(pt) {
+				dep.t = reflect.New(pt).Interface().(optionalParam).elemType()
+				dep.optional = true
+			}
+			deps = append(deps, dep)
+		}
+	}
+	// field 依赖
+	t := tPtr
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return deps, errs
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if err := checkLegacyBeanNameTag(field); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		// 调用时才获取 bean 的函数，视为延迟依赖，无法解析时由调用方在调用时处理
+		if isAutowired(field) && isProviderFunc(field) {
+			ft := field.Type.Out(0)
+			if name, err := bc.resolveBeanName(field, ft, bc.getInjectQualifier(beanName, field)); err == nil {
+				deps = append(deps, dependency{source: field.Name, beanName: name, t: ft, lazy: true, optional: true})
+			}
+			continue
+		}
+		ft, ok := bc.getFieldInjectType(field)
+		if !ok {
+			continue
+		}
+		// 集合注入所有匹配的 bean，不是对单个 bean 的依赖；指定的 beanName 已经注册时是对该 bean 的依赖
+		if !isAutowired(field) || bc.isCollectionInjection(field) {
+			continue
+		}
+		if ok, err := bc.isGuardSatisfied(field); err != nil {
+			errs = append(errs, err)
+			continue
+		} else if !ok {
+			continue
+		}
+		qualifier := bc.getInjectQualifier(beanName, field)
+		lazy := hasAutowiredOption(field, LazyOption)
+		optional := hasAutowiredOption(field, OptionalOption)
+		var fieldBeanName string
+		var err error
+		if lazy {
+			fieldBeanName, err = bc.getLazyBeanName(field, qualifier)
+		} else if !qualifier.isEmpty() {
+			fieldBeanName, err = bc.getBeanNameWithQualifier(field.Type, qualifier)
+		} else if isEmptyInterface(field.Type) && getBeanName(field) == "" {
+			err = fmt.Errorf("%v field requires a beanName", field.Type)
+		} else {
+			// 没有注册的 bean 也作为依赖返回，由调用方决定是否报错
+			fieldBeanName, err = getFieldBeanName(bc, field, ft)
+			if errors.Is(err, ErrBeanNotFound) {
+				err = nil
+			}
+		}
+		if err != nil {
+			// 严格模式下没有指定 beanName，即使是可选注入也是错误
+			if !optional || errors.Is(err, ErrNoBeanName) {
+				errs = append(errs, fmt.Errorf("field %v: %v", field.Name, err))
+			}
+			continue
+		}
+		deps = append(deps, dependency{
+			source:   field.Name,
+			beanName: fieldBeanName,
+			t:        field.Type,
+			lazy:     lazy,
+			optional: optional,
+		})
+	}
+	return deps, errs
+}
+
+// findCycleFrom 静态查找经过 beanName 的循环依赖，返回首尾都是 beanName 的依赖路径，不存在时返回 nil
+// 延迟注入的依赖不会构成创建时的循环依赖，不参与查找，无法解析的依赖同样忽略
+func (bc *BeanBeanFactory) findCycleFrom(beanName string) []string {
+	visited := map[string]bool{}
+	var path []string
+	var dfs func(current string) bool
+	dfs = func(current string) bool {
+		visited[current] = true
+		path = append(path, current)
+		deps, _ := bc.getDependencies(current)
+		for _, dep := range deps {
+			if dep.lazy {
+				continue
+			}
+			if dep.beanName == beanName {
+				path = append(path, beanName)
+				return true
+			}
+			if !visited[dep.beanName] && bc.isRegistered(dep.beanName) && dfs(dep.beanName) {
+				return true
+			}
+		}
+		path = path[:len(path)-1]
+		return false
+	}
+	if dfs(beanName) {
+		return path
+	}
+	return nil
+}
+
+// sortByDependency 按依赖关系对 beanNames 拓扑排序，被依赖的 bean 排在依赖它的 bean 之前，没有依赖关系的 bean 保持原有顺序
+// 延迟注入的依赖不影响顺序；构成循环依赖时，从先访问到的 bean 开始按需创建，由早期对象解决
+func (bc *BeanBeanFactory) sortByDependency(beanNames []string) []string {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := map[string]int{}
+	sorted := make([]string, 0, len(beanNames))
+	var visit func(beanName string)
+	visit = func(beanName string) {
+		if state[beanName] != 0 {
+			return
+		}
+		state[beanName] = visiting
+		deps, _ := bc.getDependencies(beanName)
+		for _, dep := range deps {
+			if dep.lazy || !bc.isRegistered(dep.beanName) {
+				continue
+			}
+			visit(dep.beanName)
+		}
+		state[beanName] = visited
+		sorted = append(sorted, beanName)
+	}
+	for _, beanName := range beanNames {
+		visit(beanName)
+	}
+	return sorted
+}
+
+// Dependents 获取依赖 beanName 的所有 bean，按 beanName 排序，用于修改 bean 前分析影响范围
+// 从注册信息静态解析，包含按 beanName、按类型以及限定符解析到 beanName 的 field 和构造函数参数，延迟注入也视为依赖
+func (bc *BeanBeanFactory) Dependents(beanName string) []string {
+	return bc.GetBeanDependents(beanName)
+}
+
+// GetBeanDependents 获取依赖 beanName 的所有 bean，按 beanName 排序，可以在 Rebind 前分析影响范围
+// 结果来自反向依赖索引，索引在第一次查询时构建，注册、Rebind、注销以及注册别名后失效
+func (bc *BeanBeanFactory) GetBeanDependents(beanName string) []string {
+	bc.mu.RLock()
+	beanName = bc.resolveAlias(beanName)
+	index, version := bc.dependentsIndex, bc.registryVersion
+	bc.mu.RUnlock()
+	if index == nil {
+		// 构建索引需要解析依赖，解析过程中会获取读锁，因此不能持有锁构建
+		index = bc.buildDependentsIndex()
+		bc.mu.Lock()
+		// 构建期间注册信息没有变化，索引才是有效的
+		if bc.registryVersion == version {
+			bc.dependentsIndex = index
+		}
+		bc.mu.Unlock()
+	}
+	if len(index[beanName]) == 0 {
+		return nil
+	}
+	return append([]string{}, index[beanName]...)
+}
+
+// buildDependentsIndex 构建反向依赖索引，按 beanName 遍历，value 中的 beanName 有序
+func (bc *BeanBeanFactory) buildDependentsIndex() map[string][]string {
+	index := map[string][]string{}
+	for _, name := range bc.getBeanNames() {
+		for _, dep := range bc.GetBeanDependencies(name) {
+			if dep != name {
+				index[dep] = append(index[dep], name)
+			}
+		}
+	}
+	return index
+}
+
+// invalidateDependents 注册信息变化后丢弃反向依赖索引，调用方需要持有写锁
+func (bc *BeanBeanFactory) invalidateDependents() {
+	bc.dependentsIndex = nil
+	bc.registryVersion++
+}
+
+// GetBeanDependencies 获取 bean 直接依赖的 beanName，按 field 和构造函数参数的声明顺序去重，bean 没有注册时返回 nil
+// 从注册信息静态解析，不会创建 bean，延迟注入以及尚未注册、创建时会自动注册的依赖同样返回
+func (bc *BeanBeanFactory) GetBeanDependencies(beanName string) []string {
+	beanName = bc.canonicalName(beanName)
+	if !bc.isRegistered(beanName) {
+		return nil
+	}
+	deps, _ := bc.getDependencies(beanName)
+	seen := map[string]bool{}
+	var names []string
+	for _, dep := range deps {
+		if !seen[dep.beanName] {
+			seen[dep.beanName] = true
+			names = append(names, dep.beanName)
+		}
+	}
+	return names
+}
+
+// GetBeanDependenciesTransitive 按广度优先获取 bean 直接以及间接依赖的所有 beanName，不包含 bean 本身
+// bean 没有注册时返回 ErrNotRegistered，存在无法解析的依赖时返回第一个解析错误
+// 尚未注册的依赖会包含在结果中，但无法继续展开它的依赖
+func (bc *BeanBeanFactory) GetBeanDependenciesTransitive(beanName string) ([]string, error) {
+	beanName = bc.canonicalName(beanName)
+	if !bc.isRegistered(beanName) {
+		return nil, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	visited := map[string]bool{beanName: true}
+	queue := []string{beanName}
+	var names []string
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		deps, errs := bc.getDependencies(current)
+		if len(errs) > 0 {
+			return nil, fmt.Errorf("bean %v: %w", current, errs[0])
+		}
+		for _, dep := range deps {
+			if visited[dep.beanName] {
+				continue
+			}
+			visited[dep.beanName] = true
+			names = append(names, dep.beanName)
+			if bc.isRegistered(dep.beanName) {
+				queue = append(queue, dep.beanName)
+			}
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,46 @@
+package gioc
+
+import (
+	"reflect"
+	"testing"
+)
+
+type dependedA struct {
+	x int
+}
+
+// dependentB 按 beanName 依赖 dependedA
+type dependentB struct {
+	A *dependedA `di:"dependedA"`
+}
+
+// dependentC 按类型依赖 dependedA
+type dependentC struct {
+	A *dependedA `di:""`
+}
+
+type dependentD struct {
+	B *dependentB `di:"dependentB"`
+}
+
+func TestDependents(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("dependedA", (*dependedA)(nil), Singleton),
+		NewClass("dependentB", (*dependentB)(nil), Singleton),
+		NewClass("dependentC", (*dependentC)(nil), Prototype),
+	)
+	if got, want := ioc.Dependents("dependedA"), []string{"dependentB", "dependentC"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dependents(dependedA) = %v, want %v", got, want)
+	}
+	if got := ioc.Dependents("dependentC"); len(got) != 0 {
+		t.Fatalf("Dependents(dependentC) = %v, want none", got)
+	}
+	// 注册新的 bean 后反向依赖索引失效
+	if err := ioc.Register(NewClass("dependentD", (*dependentD)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ioc.Dependents("dependentB"), []string{"dependentD"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Dependents(dependentB) = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,84 @@
+package gioc
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+type depth1 struct {
+	Next *depth2 `di:"depth2"`
+}
+type depth2 struct {
+	Next *depth3 `di:"depth3"`
+}
+type depth3 struct {
+	Next *depth4 `di:"depth4"`
+}
+type depth4 struct{}
+
+func registerDepthChain(t *testing.T, ioc *IOC, beanType BeanType) {
+	t.Helper()
+	ioc.MustRegisterAll(
+		NewClass("depth1", (*depth1)(nil), beanType),
+		NewClass("depth2", (*depth2)(nil), beanType),
+		NewClass("depth3", (*depth3)(nil), beanType),
+		NewClass("depth4", (*depth4)(nil), beanType),
+	)
+}
+
+func TestMaxDepthExceeded(t *testing.T) {
+	ioc := NewIOC(WithMaxDepth(3), WithFailFast(false))
+	registerDepthChain(t, ioc, Singleton)
+	if _, err := ioc.GetBeanE("depth1"); !errors.Is(err, ErrMaxDepthExceeded) {
+		t.Fatalf("got %v, want ErrMaxDepthExceeded", err)
+	}
+	// 从链的中间开始获取没有超过深度
+	if _, err := ioc.GetBeanE("depth2"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMaxDepthWithinLimit(t *testing.T) {
+	ioc := NewIOC(WithMaxDepth(4), WithFailFast(false))
+	registerDepthChain(t, ioc, Singleton)
+	bean, err := ioc.GetBeanE("depth1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bean.(*depth1).Next.Next.Next == nil {
+		t.Fatal("chain is not wired")
+	}
+}
+
+type slowShallow struct{}
+
+func (*slowShallow) AfterPropertiesSet(ctx context.Context) error {
+	time.Sleep(10 * time.Millisecond)
+	return nil
+}
+
+func TestMaxDepthConcurrentChainsAreIndependent(t *testing.T) {
+	ioc := NewIOC(WithMaxDepth(2), WithFailFast(false))
+	if err := ioc.Register(NewClass("shallow", (*slowShallow)(nil), Prototype)); err != nil {
+		t.Fatal(err)
+	}
+	const n = 8
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = ioc.GetBeanE("shallow")
+		}(i)
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("call %v: %v", i, err)
+		}
+	}
+}
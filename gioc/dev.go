@@ -0,0 +1,71 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// checkNamedDependencies 开发模式下注册时校验 bean 的 di 注解指定的 beanName 都已经注册
+// 注解指定的 beanName 没有注册时，存在同名限定符的 bean 也视为已注册
+func (bc *BeanBeanFactory) checkNamedDependencies(beanName string) error {
+	bc.mu.RLock()
+	t := bc.tMap[beanName]
+	bc.mu.RUnlock()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !isAutowired(field) || hasAutowiredOption(field, OptionalOption) {
+			continue
+		}
+		if ok, _ := bc.isGuardSatisfied(field); !ok {
+			continue
+		}
+		name := getBeanName(field)
+		if name == "" || bc.isRegistered(name) {
+			continue
+		}
+		if _, err := bc.getBeanNameWithQualifier(field.Type, Qualifier{Name: name}); err == nil {
+			continue
+		}
+		return fmt.Errorf("bean %v field %v: bean %v: %w", beanName, field.Name, name, ErrNotRegistered)
+	}
+	return nil
+}
+
+// checkInjection 开发模式下注入前校验 bean 类型是否兼容，以及单例 bean 是否注入了原型 bean
+// 单例 bean 只会注入一次，注入的原型 bean 实际上和单例一样只有一个实例，往往不是期望的结果
+func (bc *BeanBeanFactory) checkInjection(beanName string, field reflect.StructField, fieldBeanName string, fieldBeanType BeanType) {
+	bc.mu.RLock()
+	depT, exist := bc.tMap[fieldBeanName]
+	depType := bc.btMap[fieldBeanName]
+	bc.mu.RUnlock()
+	if !exist {
+		return
+	}
+	if !isInjectable(depT, field.Type) {
+		panic(fmt.Errorf("bean %v field %v: bean %v of type %v is not assignable to %v: %w", beanName, field.Name, fieldBeanName, depT, field.Type, ErrTypeMismatch))
+	}
+	if fieldBeanType != Invalid {
+		depType = fieldBeanType
+	}
+	if isSingleton(bc.getBeanType(beanName)) && isPrototype(depType) {
+		panic(fmt.Errorf("bean %v field %v: singleton bean can not inject prototype bean %v", beanName, field.Name, fieldBeanName))
+	}
+}
+
+// getClassType 获取 class 注册的 bean 类型，使用构造函数并且没有指定类型时返回构造函数返回值类型
+func getClassType(class *Class) reflect.Type {
+	t, ok := class.i.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(class.i)
+	}
+	if t == nil && class.constructor != nil {
+		t, _ = checkConstructor(class.constructor, nil)
+	}
+	return t
+}
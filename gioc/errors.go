@@ -0,0 +1,60 @@
+package gioc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotRegistered bean 没有注册
+var ErrNotRegistered = errors.New("bean is not registered")
+
+// ErrTypeMismatch bean 的类型与期望的类型不匹配
+var ErrTypeMismatch = errors.New("bean type mismatch")
+
+// ErrWouldCreateCircularDependency 注册的 bean 会与已注册的 bean 构成无法解决的循环依赖
+var ErrWouldCreateCircularDependency = errors.New("bean would create circular dependency")
+
+// ErrMaxDepthExceeded bean 创建的嵌套深度超过了 WithMaxDepth 设置的最大深度
+var ErrMaxDepthExceeded = errors.New("max dependency depth exceeded")
+
+// ErrFrozen 容器已经冻结，不允许再注册或者替换 bean
+var ErrFrozen = errors.New("container is frozen")
+
+// ErrClosed 容器已经开始关闭，不再创建或者获取 bean
+var ErrClosed = errors.New("container is closed")
+
+// ErrNoTag field 没有 di 注解，不需要注入
+var ErrNoTag = errors.New("field has no di tag")
+
+// ErrBeanNotFound field 有 di 注解，但是需要的 bean 没有注册，同时也是 ErrNotRegistered
+var ErrBeanNotFound = fmt.Errorf("field bean not found: %w", ErrNotRegistered)
+
+// ErrNoBeanName 开启 WithStrictByName 时 di 注解没有指定 beanName
+var ErrNoBeanName = errors.New("di tag must name a bean in strict by-name mode")
+
+// ErrAliasTypeMismatch 别名已经指向其他 bean，新 bean 的类型不能赋值给原来 bean 的类型
+var ErrAliasTypeMismatch = errors.New("alias type mismatch")
+
+// ErrTimeout bean 没有在指定的时间内创建完成
+var ErrTimeout = errors.New("bean creation timed out")
+
+// ErrAlreadyCreated 单例 bean 已经创建，不能再修改作用域
+var ErrAlreadyCreated = errors.New("bean is already created")
+
+// MultiError 多个错误的集合，用于批量操作中收集每一项的错误
+type MultiError []error
+
+// Error
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%v errors:\n%v", len(m), strings.Join(msgs, "\n"))
+}
+
+// Unwrap 返回所有错误，errors.Is 和 errors.As 会逐个检查
+func (m MultiError) Unwrap() []error {
+	return m
+}
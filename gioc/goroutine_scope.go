@@ -0,0 +1,106 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// tokenScope 协程作用域，按调用方传入的作用域令牌缓存 bean，同一个令牌内每个 bean 只会创建一次
+// golang 没有公开的 goroutine id，通过运行时栈解析的 id 并不可靠，因此由调用方显式传入令牌，
+// 例如每个 worker goroutine 使用自己的令牌，结束时调用 ReleaseScoped 释放
+type tokenScope struct {
+	mu    sync.Mutex
+	beans map[interface{}]map[string]interface{}
+}
+
+// newTokenScope 实例化一个协程作用域
+func newTokenScope() *tokenScope {
+	return &tokenScope{
+		beans: map[interface{}]map[string]interface{}{},
+	}
+}
+
+// get 获取令牌内的 bean，不存在时调用 objectFactory 创建
+func (ts *tokenScope) get(token interface{}, beanName string, objectFactory func() interface{}) interface{} {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	if bean, exist := ts.beans[token][beanName]; exist {
+		return bean
+	}
+	bean := objectFactory()
+	if bean != nil {
+		if ts.beans[token] == nil {
+			ts.beans[token] = map[string]interface{}{}
+		}
+		ts.beans[token][beanName] = bean
+	}
+	return bean
+}
+
+// release 移除令牌内的所有 bean 并返回
+func (ts *tokenScope) release(token interface{}) map[string]interface{} {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	beans := ts.beans[token]
+	delete(ts.beans, token)
+	return beans
+}
+
+// isGoroutine 判断是否是协程作用域 bean
+func isGoroutine(beanType BeanType) bool {
+	return beanType == Goroutine
+}
+
+// checkToken 校验作用域令牌，令牌需要作为 map key，因此必须是可比较的类型
+func checkToken(token interface{}) error {
+	if token == nil {
+		return fmt.Errorf("scope token is nil")
+	}
+	if t := reflect.TypeOf(token); !t.Comparable() {
+		return fmt.Errorf("scope token of type %v is not comparable", t)
+	}
+	return nil
+}
+
+// GetBeanScoped 根据作用域令牌获取 bean，协程作用域的 bean 同一个令牌只会创建一次，不同令牌获取到不同的实例
+// 其他作用域的 bean 忽略令牌，与 GetBeanE 相同
+func (bc *BeanBeanFactory) GetBeanScoped(token interface{}, beanName string) (bean interface{}, err error) {
+	if err := checkToken(token); err != nil {
+		return nil, err
+	}
+	beanType := bc.getBeanType(beanName)
+	if beanType == Invalid {
+		return nil, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if !isGoroutine(beanType) {
+		return bc.GetBeanE(beanName)
+	}
+	if err := bc.acquireCall(beanName); err != nil {
+		return nil, err
+	}
+	defer bc.releaseCall()
+	defer bc.recoverCreatePanic(&err)
+	bean = bc.tokenScope.get(token, beanName, func() interface{} {
+		return bc.createBean(context.Background(), beanName, Goroutine, false)
+	})
+	return bean, nil
+}
+
+// ReleaseScoped 释放作用域令牌内的所有 bean，按 beanName 排序调用销毁回调
+func (bc *BeanBeanFactory) ReleaseScoped(token interface{}) {
+	if checkToken(token) != nil {
+		return
+	}
+	beans := bc.tokenScope.release(token)
+	beanNames := make([]string, 0, len(beans))
+	for beanName := range beans {
+		beanNames = append(beanNames, beanName)
+	}
+	sort.Strings(beanNames)
+	for _, beanName := range beanNames {
+		bc.destroySingleton(beanName, beans[beanName])
+	}
+}
@@ -0,0 +1,68 @@
+package gioc
+
+import (
+	"sync"
+	"testing"
+)
+
+type worker struct {
+	x int
+}
+
+func newGoroutineIOC() *IOC {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("worker", (*worker)(nil), Goroutine))
+	return ioc
+}
+
+func TestGetBeanScoped(t *testing.T) {
+	ioc := newGoroutineIOC()
+	type token struct{ id int }
+	a1, err := ioc.GetBeanScoped(token{1}, "worker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, _ := ioc.GetBeanScoped(token{1}, "worker")
+	b, _ := ioc.GetBeanScoped(token{2}, "worker")
+	if a1 != a2 {
+		t.Fatal("same token returned two instances")
+	}
+	if a1 == b {
+		t.Fatal("two tokens returned the same instance")
+	}
+	// 释放令牌后再次获取会创建新的实例
+	ioc.ReleaseScoped(token{1})
+	if again, _ := ioc.GetBeanScoped(token{1}, "worker"); again == a1 {
+		t.Fatal("released token returned the old instance")
+	}
+	if _, err := ioc.GetBeanScoped(nil, "worker"); err == nil {
+		t.Fatal("want error for a nil token")
+	}
+}
+
+func TestGetBeanScopedConcurrent(t *testing.T) {
+	ioc := newGoroutineIOC()
+	const n = 8
+	beans := make([][2]interface{}, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			// 每个 goroutine 使用自己的令牌
+			beans[i][0], _ = ioc.GetBeanScoped(i, "worker")
+			beans[i][1], _ = ioc.GetBeanScoped(i, "worker")
+		}(i)
+	}
+	wg.Wait()
+	seen := map[interface{}]bool{}
+	for i, pair := range beans {
+		if pair[0] == nil || pair[0] != pair[1] {
+			t.Fatalf("goroutine %d got %p and %p, want one instance", i, pair[0], pair[1])
+		}
+		if seen[pair[0]] {
+			t.Fatalf("goroutine %d shares an instance with another goroutine", i)
+		}
+		seen[pair[0]] = true
+	}
+}
@@ -0,0 +1,108 @@
+package gioc
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// GraphFormatDOT Graphviz DOT 格式
+	GraphFormatDOT = "dot"
+	// GraphFormatMermaid Mermaid 格式
+	GraphFormatMermaid = "mermaid"
+)
+
+// graphEdge 依赖图中的一条边
+type graphEdge struct {
+	from  string
+	to    string
+	label string
+	lazy  bool
+}
+
+// ExportGraph 将已注册 bean 的静态依赖图以 DOT 或者 Mermaid 格式写入 w，不会创建任何 bean
+// 单例 bean 为矩形，原型 bean 为椭圆，自定义作用域 bean 为菱形，边的标签为 field 名或者构造函数参数，延迟注入为虚线
+func (bc *BeanBeanFactory) ExportGraph(w io.Writer, format string) error {
+	beanNames := bc.getBeanNames()
+	var edges []graphEdge
+	nodes := map[string]bool{}
+	for _, beanName := range beanNames {
+		nodes[beanName] = true
+		deps, _ := bc.getDependencies(beanName)
+		for _, dep := range deps {
+			// 未注册的依赖会在创建时自动注册，也需要画出来
+			nodes[dep.beanName] = true
+			edges = append(edges, graphEdge{from: beanName, to: dep.beanName, label: dep.source, lazy: dep.lazy})
+		}
+	}
+	var unregistered []string
+	for beanName := range nodes {
+		if !bc.isRegistered(beanName) {
+			unregistered = append(unregistered, beanName)
+		}
+	}
+	sort.Strings(unregistered)
+	beanNames = append(beanNames, unregistered...)
+	var b strings.Builder
+	switch format {
+	case GraphFormatDOT:
+		bc.writeDOT(&b, beanNames, edges)
+	case GraphFormatMermaid:
+		bc.writeMermaid(&b, beanNames, edges)
+	default:
+		return fmt.Errorf("graph format %v is not supported", format)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeDOT 写入 Graphviz DOT 格式的依赖图
+func (bc *BeanBeanFactory) writeDOT(b *strings.Builder, beanNames []string, edges []graphEdge) {
+	b.WriteString("digraph beans {\n")
+	for _, beanName := range beanNames {
+		shape := "box"
+		switch beanType := bc.getBeanType(beanName); {
+		case isPrototype(beanType):
+			shape = "ellipse"
+		case beanType != Invalid && !isSingleton(beanType):
+			shape = "diamond"
+		}
+		fmt.Fprintf(b, "\t%q [shape=%v];\n", beanName, shape)
+	}
+	for _, e := range edges {
+		style := ""
+		if e.lazy {
+			style = ", style=dashed"
+		}
+		fmt.Fprintf(b, "\t%q -> %q [label=%q%v];\n", e.from, e.to, e.label, style)
+	}
+	b.WriteString("}\n")
+}
+
+// writeMermaid 写入 Mermaid 格式的依赖图，节点 id 使用序号，避免 beanName 中的特殊字符
+func (bc *BeanBeanFactory) writeMermaid(b *strings.Builder, beanNames []string, edges []graphEdge) {
+	b.WriteString("graph LR\n")
+	ids := make(map[string]string, len(beanNames))
+	for i, beanName := range beanNames {
+		id := fmt.Sprintf("n%v", i)
+		ids[beanName] = id
+		label := strings.ReplaceAll(beanName, `"`, "#quot;")
+		switch beanType := bc.getBeanType(beanName); {
+		case isPrototype(beanType):
+			fmt.Fprintf(b, "\t%v([\"%v\"])\n", id, label)
+		case beanType != Invalid && !isSingleton(beanType):
+			fmt.Fprintf(b, "\t%v{\"%v\"}\n", id, label)
+		default:
+			fmt.Fprintf(b, "\t%v[\"%v\"]\n", id, label)
+		}
+	}
+	for _, e := range edges {
+		arrow := "-->"
+		if e.lazy {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(b, "\t%v %v|%v| %v\n", ids[e.from], arrow, e.label, ids[e.to])
+	}
+}
@@ -0,0 +1,89 @@
+package gioc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type graphA struct {
+	B *graphB `di:"graphB"`
+}
+
+type graphB struct {
+	C *graphC `di:"graphC"`
+}
+
+type graphC struct {
+	x int
+}
+
+func newGraphIOC() *IOC {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("graphA", (*graphA)(nil), Singleton),
+		NewClass("graphB", (*graphB)(nil), Prototype),
+		NewClass("graphC", (*graphC)(nil), Singleton),
+	)
+	return ioc
+}
+
+func assertContains(t *testing.T, out string, wants ...string) {
+	t.Helper()
+	for _, want := range wants {
+		if !strings.Contains(out, want) {
+			t.Fatalf("output does not contain %q:\n%v", want, out)
+		}
+	}
+}
+
+func TestExportGraphDOT(t *testing.T) {
+	ioc := newGraphIOC()
+	var buf bytes.Buffer
+	if err := ioc.ExportGraph(&buf, GraphFormatDOT); err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, buf.String(),
+		"digraph beans {",
+		`"graphA" [shape=box];`,
+		`"graphB" [shape=ellipse];`,
+		`"graphC" [shape=box];`,
+		`"graphA" -> "graphB" [label="B"];`,
+		`"graphB" -> "graphC" [label="C"];`,
+	)
+	// 导出依赖图不会创建 bean
+	if n := ioc.GetCreatedSingletonCount(); n != 0 {
+		t.Fatalf("created %d singletons, want 0", n)
+	}
+}
+
+func TestExportGraphMermaid(t *testing.T) {
+	ioc := newGraphIOC()
+	var buf bytes.Buffer
+	if err := ioc.ExportGraph(&buf, GraphFormatMermaid); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	ids := map[string]string{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		for _, name := range []string{"graphA", "graphB", "graphC"} {
+			if strings.Contains(line, `"`+name+`"`) {
+				ids[name] = line[:strings.IndexAny(line, "[({")]
+			}
+		}
+	}
+	if len(ids) != 3 {
+		t.Fatalf("got node ids %v, want 3 nodes:\n%v", ids, out)
+	}
+	assertContains(t, out,
+		"graph LR",
+		ids["graphA"]+`["graphA"]`,
+		ids["graphB"]+`(["graphB"])`,
+		ids["graphA"]+" -->|B| "+ids["graphB"],
+		ids["graphB"]+" -->|C| "+ids["graphC"],
+	)
+	if err := ioc.ExportGraph(&buf, "svg"); err == nil {
+		t.Fatal("want error for an unsupported format")
+	}
+}
@@ -0,0 +1,65 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// InterfaceAdvice 接口通知，接收原始 bean，返回实现了该接口的代理，只作用于以该接口注入的 field
+// 与 LazyProxyFactory 相同，接口的代理实现需要由用户提供，例如：
+//
+//	type tracedCache struct{ target Cache }
+//	func (c *tracedCache) Get(key string) string { defer trace("Cache.Get")(); return c.target.Get(key) }
+type InterfaceAdvice func(target interface{}) interface{}
+
+// RegisterInterfaceAdvice 为 bean 注册只在以接口 iface 注入时生效的通知，iface 格式为 (*Cache)(nil)
+// bean 实现了多个接口时，类型为 iface 的 field 注入代理，其他类型的 field 以及 GetBean 获取到的仍然是原始 bean
+// 元素类型为 iface 的集合 field、延迟代理、func() T 以及 func() (T, error)、func(key string) T 提供者函数同样得到代理，
+// 构造函数参数不应用通知；每个注入点各自包装一次，同一个 bean、同一个接口注册多次时按注册顺序层层包装
+func (bc *BeanBeanFactory) RegisterInterfaceAdvice(beanName string, iface interface{}, advice InterfaceAdvice) error {
+	t, ok := iface.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(iface)
+	}
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Interface {
+		return fmt.Errorf("advice type %v of bean %v is not an interface", t, beanName)
+	}
+	if advice == nil {
+		return fmt.Errorf("advice of bean %v for %v is nil", beanName, t)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	beanT, exist := bc.tMap[beanName]
+	if !exist {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if beanT.Kind() != reflect.Interface && !beanT.Implements(t) && !reflect.PtrTo(beanT).Implements(t) {
+		return fmt.Errorf("bean %v of type %v does not implement %v", beanName, beanT, t)
+	}
+	if bc.adviceMap[beanName] == nil {
+		bc.adviceMap[beanName] = map[reflect.Type][]InterfaceAdvice{}
+	}
+	bc.adviceMap[beanName][t] = append(bc.adviceMap[beanName][t], advice)
+	return nil
+}
+
+// adviseForInterface 对以接口 iface 注入的 bean 应用通知，没有注册通知或者 iface 不是接口时返回原始 bean
+func (bc *BeanBeanFactory) adviseForInterface(beanName string, iface reflect.Type, bean interface{}) interface{} {
+	bc.mu.RLock()
+	advices := bc.adviceMap[beanName][iface]
+	bc.mu.RUnlock()
+	if bean == nil {
+		return bean
+	}
+	for _, advice := range advices {
+		proxy := advice(bean)
+		if proxy == nil || !reflect.TypeOf(proxy).Implements(iface) {
+			panic(fmt.Errorf("advice of bean %v returns %T, not implements %v", beanName, proxy, iface))
+		}
+		bean = proxy
+	}
+	return bean
+}
@@ -0,0 +1,96 @@
+package gioc
+
+import (
+	"testing"
+)
+
+// dualService 同时实现了 Cache 和 Handler
+type dualService struct {
+	x int
+}
+
+func (*dualService) Name() string   { return "dual" }
+func (*dualService) Handle() string { return "dual" }
+
+// tracedCache 只代理 Cache 接口
+type tracedCache struct {
+	target Cache
+}
+
+func (c *tracedCache) Name() string { return "traced:" + c.target.Name() }
+
+// lazyCache Cache 的延迟代理
+type lazyCache struct {
+	target func() interface{}
+}
+
+func (c *lazyCache) Name() string { return c.target().(Cache).Name() }
+
+type advisedUser struct {
+	Cache    Cache                  `di:"dual"`
+	Handler  Handler                `di:"dual"`
+	Caches   []Cache                `di:""`
+	Lazy     Cache                  `di:"dual,lazy"`
+	LazyFunc func() Cache           `di:"dual,lazy"`
+	Provider func() (Cache, error)  `di:"dual"`
+	Keyed    func(key string) Cache `di:"dual"`
+	Handlers map[string]Handler     `di:""`
+}
+
+func TestInterfaceAdvice(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("dual", (*dualService)(nil), Singleton),
+		NewClass("advisedUser", (*advisedUser)(nil), Singleton),
+	)
+	if err := ioc.RegisterLazyProxy((*Cache)(nil), func(target func() interface{}) interface{} {
+		return &lazyCache{target: target}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err := ioc.RegisterInterfaceAdvice("dual", (*Cache)(nil), func(target interface{}) interface{} {
+		return &tracedCache{target: target.(Cache)}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := ioc.GetBean("dual")
+	if _, ok := raw.(*dualService); !ok {
+		t.Fatalf("GetBean returned %T, want the original bean", raw)
+	}
+	user := ioc.GetBean("advisedUser").(*advisedUser)
+	// 同一个 bean 注入两个不同接口的 field，只有注册了通知的接口得到代理
+	if user.Handler != raw {
+		t.Fatalf("Handler field got %T, want the original bean", user.Handler)
+	}
+	if len(user.Handlers) != 1 || user.Handlers["dual"] != raw {
+		t.Fatalf("Handlers = %v, want the original bean", user.Handlers)
+	}
+	provided, err := user.Provider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, cache := range map[string]Cache{
+		"field":    user.Cache,
+		"slice":    user.Caches[0],
+		"lazy":     user.Lazy,
+		"lazyFunc": user.LazyFunc(),
+		"provider": provided,
+		"keyed":    user.Keyed("tenant"),
+	} {
+		if got := cache.Name(); got != "traced:dual" {
+			t.Errorf("%v injection: Name() = %q, want the advised proxy", name, got)
+		}
+	}
+}
+
+func TestInterfaceAdviceRequiresImplementation(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("counted", (*counted)(nil), Singleton))
+	err := ioc.RegisterInterfaceAdvice("counted", (*Cache)(nil), func(target interface{}) interface{} {
+		return target
+	})
+	if err == nil {
+		t.Fatal("expected error advising an interface the bean does not implement")
+	}
+}
@@ -1,10 +1,43 @@
 package gioc
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+	"time"
+)
+
 // Class 存储要注册的 bean 的信息
 type Class struct {
 	beanName string
 	i        interface{}
 	beanType BeanType
+	// 限定符，同一接口存在多个实现时用于区分注入哪一个
+	qualifier Qualifier
+	// field 注入时使用的限定符，key 为 field 名，优先于 di 注解
+	fieldQualifiers map[string]Qualifier
+	// 构造函数，不为空时使用构造函数创建 bean 而不是 reflect.New
+	constructor interface{}
+	// 构造函数参数，"@beanName" 格式的 string 参数会被替换为容器中对应的 bean
+	constructorArgs []interface{}
+	// 提供者，不为空时直接使用提供者返回的实例作为 bean，用于注册接口 bean
+	supplier func() interface{}
+	// 初始化方法名，属性注入完成后调用，方法签名为 func() 或者 func() error
+	initMethod string
+	// 销毁方法名，单例 bean 销毁时调用，方法签名为 func() 或者 func() error
+	destroyMethod string
+	// 销毁优先级，关闭容器时优先级高的 bean 先销毁，优先级相同时按创建顺序的逆序销毁
+	destroyPriority int
+	// 首选 bean，按类型注入时存在多个同类型 bean 优先选择首选 bean
+	primary bool
+	// 属性值，bean 实例化后、属性注入前赋值给同名的导出 field
+	properties map[string]interface{}
+	// 注册条件，不为空时 bean 在 Refresh 时满足所有条件才会注册
+	conditions []Condition
+	// 注册顺序，由 beanFactory 在注册时设置
+	order int
 }
 
 // NewClass
@@ -16,30 +49,559 @@ func NewClass(beanName string, i interface{}, beanType BeanType) *Class {
 	}
 }
 
+// ClassWithConstructor 使用构造函数创建 bean，bean 类型为构造函数的返回值类型，
+// 构造函数的参数按类型从容器中获取，适用于没有导出 field、无法通过 field 注入依赖的 bean，例如
+// func NewService(repo *Repo, cache Cache) *Service
+func ClassWithConstructor(beanName string, constructor interface{}, beanType BeanType) *Class {
+	return NewClass(beanName, nil, beanType).SetConstructor(constructor)
+}
+
+// NewFactoryClass 使用工厂函数创建 bean，names 依次为工厂函数每个参数对应的 beanName，
+// 用于解决多个参数类型相同、无法按类型区分的问题，例如
+// NewFactoryClass("svc", func(primary, replica *DB) *Service, Singleton, []string{"primaryDB", "replicaDB"})
+// names 的个数与工厂函数的参数个数不一致时，注册返回错误
+func NewFactoryClass(beanName string, factory interface{}, beanType BeanType, names []string) *Class {
+	args := make([]interface{}, len(names))
+	for i, name := range names {
+		args[i] = BeanRefPrefix + name
+	}
+	return NewClass(beanName, nil, beanType).SetConstructor(factory, args...)
+}
+
+// SetQualifier 设置 bean 的限定符
+func (c *Class) SetQualifier(qualifier string) *Class {
+	c.qualifier.Name = qualifier
+	return c
+}
+
+// SetQualifierAttribute 设置 bean 限定符的属性
+func (c *Class) SetQualifierAttribute(key, value string) *Class {
+	if c.qualifier.Attributes == nil {
+		c.qualifier.Attributes = map[string]string{}
+	}
+	c.qualifier.Attributes[key] = value
+	return c
+}
+
+// SetFieldQualifier 设置 bean 的 field 注入时使用的限定符，优先于 field 的 di 注解
+func (c *Class) SetFieldQualifier(fieldName string, qualifier Qualifier) *Class {
+	if c.fieldQualifiers == nil {
+		c.fieldQualifiers = map[string]Qualifier{}
+	}
+	c.fieldQualifiers[fieldName] = qualifier
+	return c
+}
+
+// SetConstructor 设置 bean 的构造函数以及构造函数参数
+// constructor 必须是函数，返回值为 bean 或者 (bean, error)
+func (c *Class) SetConstructor(constructor interface{}, args ...interface{}) *Class {
+	c.constructor = constructor
+	c.constructorArgs = args
+	return c
+}
+
+// SetSupplier 设置 bean 的提供者
+func (c *Class) SetSupplier(supplier func() interface{}) *Class {
+	c.supplier = supplier
+	return c
+}
+
+// SetInitMethod 设置 bean 的初始化方法
+func (c *Class) SetInitMethod(initMethod string) *Class {
+	c.initMethod = initMethod
+	return c
+}
+
+// SetDestroyMethod 设置 bean 的销毁方法
+func (c *Class) SetDestroyMethod(destroyMethod string) *Class {
+	c.destroyMethod = destroyMethod
+	return c
+}
+
+// SetDestroyPriority 设置 bean 的销毁优先级，默认为 0，优先级高的先销毁
+// 例如创建较早但依赖方较多的连接池可以设置负的优先级，保证最后销毁
+func (c *Class) SetDestroyPriority(destroyPriority int) *Class {
+	c.destroyPriority = destroyPriority
+	return c
+}
+
+// SetPrimary 设置 bean 是否为首选 bean
+func (c *Class) SetPrimary(primary bool) *Class {
+	c.primary = primary
+	return c
+}
+
+// SetProperty 设置 bean 的属性值，value 必须能够赋值或者转换为 field 的类型
+func (c *Class) SetProperty(name string, value interface{}) *Class {
+	if c.properties == nil {
+		c.properties = map[string]interface{}{}
+	}
+	c.properties[name] = value
+	return c
+}
+
+// SetConditions 设置 bean 的注册条件，Refresh 时所有条件都满足才会注册
+func (c *Class) SetConditions(conditions ...Condition) *Class {
+	c.conditions = conditions
+	return c
+}
+
 // ioc 容器
 type IOC struct {
 	// beanFactory 维护一个 bean 工厂
 	beanFactory BeanFactory
+	// 关闭信号，用于停止后台 goroutine
+	done chan struct{}
+	// 保证只关闭一次
+	closeOnce sync.Once
+	// 后台 goroutine
+	wg sync.WaitGroup
+	// 是否已经冻结，冻结后不允许再注册或者替换 bean，由 BeanFactoryBuilder.Build 设置
+	frozen bool
 }
 
 // NewIOC 实例化一个 IOC
 func NewIOC(opts ...Option) *IOC {
 	return &IOC{
 		beanFactory: NewBeanFactory(opts...),
+		done:        make(chan struct{}),
 	}
 }
 
 // Register 调用 bean 工厂 注册一个 bean
 func (ioc *IOC) Register(class *Class) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
 	return ioc.beanFactory.Register(class)
 }
 
+// RegisterFromStructTags 调用 bean 工厂 按结构体的 gioc 注解批量注册 bean，例如：
+//
+//	err := ioc.RegisterFromStructTags(&UserService{}, &UserDao{})
+func (ioc *IOC) RegisterFromStructTags(vals ...interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterFromStructTags(vals...)
+}
+
+// SetBeanScope 调用 bean 工厂 修改已注册 bean 的作用域，单例 bean 已经创建时返回 ErrAlreadyCreated
+func (ioc *IOC) SetBeanScope(beanName string, newScope BeanType, opts ...SetScopeOption) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.SetBeanScope(beanName, newScope, opts...)
+}
+
+// MustRegister 调用 bean 工厂 注册一个 bean，注册失败时 panic，用于 init() 等无法处理 error 的地方
+func (ioc *IOC) MustRegister(class *Class) {
+	if err := ioc.Register(class); err != nil {
+		panic(fmt.Errorf("gioc: failed to register bean '%v': %w", class.beanName, err))
+	}
+}
+
+// MustRegisterAll 按顺序注册多个 bean，任意一个注册失败时 panic
+func (ioc *IOC) MustRegisterAll(classes ...*Class) {
+	for _, class := range classes {
+		ioc.MustRegister(class)
+	}
+}
+
+// RegisterBeanProcessor 调用 bean 工厂 注册 bean 处理器，处理器会作用于之后创建的所有 bean
+func (ioc *IOC) RegisterBeanProcessor(class *Class) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterBeanProcessor(class)
+}
+
+// RegisterBeanProcessorFunc 调用 bean 工厂 注册只处理 phase 阶段的函数式 bean 处理器，不需要实现 BeanProcessor 的所有方法
+func (ioc *IOC) RegisterBeanProcessorFunc(phase ProcessorPhase, fn func(beanName string, bean interface{}) interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterBeanProcessorFunc(phase, fn)
+}
+
+// RegisterValueBean 调用 bean 工厂 将已经创建好的任意类型的值注册为单例 bean
+func (ioc *IOC) RegisterValueBean(beanName string, value interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterValueBean(beanName, value)
+}
+
+// RegisterInterfaceBean 调用 bean 工厂 注册一个由 supplier 提供实现的接口单例 bean
+func (ioc *IOC) RegisterInterfaceBean(beanName string, ifaceType reflect.Type, supplier func() interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterInterfaceBean(beanName, ifaceType, supplier)
+}
+
+// RegisterFactory 注册一个由工厂函数创建的 bean，工厂函数接收当前容器，可以从中获取其他 bean，返回的 error 会被包装后传递给获取方
+// 工厂函数作为 Class 的提供者保存，而不是单独维护一份 beanName 到工厂函数的 map，这样 bean 定义在事务、Rebind 中复制时工厂函数会一起复制；
+// 三级缓存中的 factoryMap 用于解决循环依赖，与这里的工厂函数无关，仍然保留
+// 工厂函数返回值的类型在创建前无法确定，因此该 bean 只能按 beanName 注入，例如 di:"cache"，不能按类型注入
+func (ioc *IOC) RegisterFactory(beanName string, beanType BeanType, factory func(ioc *IOC) (interface{}, error)) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	if factory == nil {
+		return fmt.Errorf("bean %v: factory is nil", beanName)
+	}
+	supplier := func() interface{} {
+		bean, err := factory(ioc)
+		if err != nil {
+			panic(fmt.Errorf("factory of bean %v failed: %w", beanName, err))
+		}
+		return bean
+	}
+	return ioc.beanFactory.Register(NewClass(beanName, anyType, beanType).SetSupplier(supplier))
+}
+
+// RegisterScope 调用 bean 工厂 注册自定义作用域
+func (ioc *IOC) RegisterScope(name string, scope Scope) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterScope(name, scope)
+}
+
+// RegisterLazyProxy 调用 bean 工厂 注册接口的延迟代理工厂
+func (ioc *IOC) RegisterLazyProxy(iface interface{}, factory LazyProxyFactory) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterLazyProxy(iface, factory)
+}
+
+// RegisterBeanAlias 调用 bean 工厂 为已注册的 bean 注册别名，别名已经指向类型不兼容的 bean 时返回 ErrAliasTypeMismatch
+func (ioc *IOC) RegisterBeanAlias(alias string, beanName string, opts ...AliasOption) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterBeanAlias(alias, beanName, opts...)
+}
+
+// RegisterInterfaceAdvice 调用 bean 工厂 为 bean 注册只在以接口 iface 注入时生效的通知
+func (ioc *IOC) RegisterInterfaceAdvice(beanName string, iface interface{}, advice InterfaceAdvice) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterInterfaceAdvice(beanName, iface, advice)
+}
+
 // GetBean 调用 bean 工厂 获取 bean
 func (ioc *IOC) GetBean(beanName string) interface{} {
 	return ioc.beanFactory.GetBean(beanName)
 }
 
-// GetBeanFactory
+// GetBeanE 调用 bean 工厂 获取 bean，关闭 failFast 时创建失败以 error 返回
+func (ioc *IOC) GetBeanE(beanName string) (interface{}, error) {
+	return ioc.beanFactory.GetBeanE(beanName)
+}
+
+// GetBeanWithTimeout 调用 bean 工厂 获取 bean，创建超过 timeout 时返回 ErrTimeout，用于初始化可能阻塞的 bean
+func (ioc *IOC) GetBeanWithTimeout(beanName string, timeout time.Duration) (interface{}, error) {
+	return ioc.beanFactory.GetBeanWithTimeout(beanName, timeout)
+}
+
+// GetBeanContext 调用 bean 工厂 获取 bean，创建 bean 时将 ctx 传递给构造函数以及 InitializingBean
+func (ioc *IOC) GetBeanContext(ctx context.Context, beanName string) (interface{}, error) {
+	return ioc.beanFactory.GetBeanContext(ctx, beanName)
+}
+
+// LookupBean 调用 bean 工厂 获取 bean，同时返回 bean 是否已经注册，例如：
+//
+//	if bean, ok := ioc.LookupBean("cache"); ok { ... }
+func (ioc *IOC) LookupBean(name string) (interface{}, bool) {
+	return ioc.beanFactory.LookupBean(name)
+}
+
+// GetBeanWithFallback 调用 bean 工厂 获取 bean，只有 bean 没有注册时才使用 fallback 的返回值
+func (ioc *IOC) GetBeanWithFallback(name string, fallback func() interface{}) interface{} {
+	return ioc.beanFactory.GetBeanWithFallback(name, fallback)
+}
+
+// RegisterType 调用 bean 工厂 注册可以在 bean 描述文件中通过名称引用的类型
+func (ioc *IOC) RegisterType(name string, i interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.RegisterType(name, i)
+}
+
+// LoadDefinitions 调用 bean 工厂 从描述文件加载并注册 bean 定义
+func (ioc *IOC) LoadDefinitions(r io.Reader, format string) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.LoadDefinitions(r, format)
+}
+
+// GetBeanSnapshot 调用 bean 工厂 获取 bean 的深拷贝，用于在容器外安全地使用 bean 的值
+func (ioc *IOC) GetBeanSnapshot(beanName string) interface{} {
+	return ioc.beanFactory.GetBeanSnapshot(beanName)
+}
+
+// GetBeanScoped 调用 bean 工厂 根据作用域令牌获取 bean，协程作用域的 bean 每个令牌一个实例
+func (ioc *IOC) GetBeanScoped(token interface{}, beanName string) (interface{}, error) {
+	return ioc.beanFactory.GetBeanScoped(token, beanName)
+}
+
+// ReleaseScoped 调用 bean 工厂 释放作用域令牌内的所有 bean
+func (ioc *IOC) ReleaseScoped(token interface{}) {
+	ioc.beanFactory.ReleaseScoped(token)
+}
+
+// GetBeanKeyed 调用 bean 工厂 根据 key 获取 bean，键控作用域的 bean 每个 key 一个实例
+func (ioc *IOC) GetBeanKeyed(key string, beanName string) (interface{}, error) {
+	return ioc.beanFactory.GetBeanKeyed(key, beanName)
+}
+
+// ReleaseKeyed 调用 bean 工厂 释放 key 对应的所有 bean
+func (ioc *IOC) ReleaseKeyed(key string) {
+	ioc.beanFactory.ReleaseKeyed(key)
+}
+
+// GetBeanAs 调用 bean 工厂 获取 bean 并赋值给 target 指向的变量，类型不匹配时返回 ErrTypeMismatch
+func (ioc *IOC) GetBeanAs(beanName string, target interface{}) error {
+	return ioc.beanFactory.GetBeanAs(beanName, target)
+}
+
+// InjectInto 调用 bean 工厂 为未注册的外部对象注入依赖，target 必须是结构体指针
+func (ioc *IOC) InjectInto(target interface{}) error {
+	return ioc.beanFactory.InjectInto(target)
+}
+
+// GetOrCreate 调用 bean 工厂 获取 bean，bean 未注册时先注册，冻结的容器中 bean 未注册时返回 ErrFrozen
+func (ioc *IOC) GetOrCreate(name string, class *Class) (interface{}, error) {
+	if ioc.frozen && !ioc.beanFactory.isRegistered(name) {
+		return nil, ErrFrozen
+	}
+	return ioc.beanFactory.GetOrCreate(name, class)
+}
+
+// GetOrRegister 获取 bean，bean 未注册时先以 i、beanType 注册，已经注册时直接返回已有的 bean，用于脚本、测试等快速原型场景
+// 注册或者创建失败时打印错误并返回 nil，冻结的容器不会注册新的 bean
+func (ioc *IOC) GetOrRegister(beanName string, i interface{}, beanType BeanType) interface{} {
+	if ioc.frozen {
+		return ioc.GetBean(beanName)
+	}
+	bean, err := ioc.GetOrCreate(beanName, NewClass(beanName, i, beanType))
+	if err != nil {
+		fmt.Println(err)
+		return nil
+	}
+	return bean
+}
+
+// BorrowBean 调用 bean 工厂 从对象池借用线程作用域的 bean，使用完毕后调用返回的函数归还
+func (ioc *IOC) BorrowBean(beanName string) (interface{}, func()) {
+	return ioc.beanFactory.BorrowBean(beanName)
+}
+
+// ReleaseBean 调用 bean 工厂 归还原型 bean
+func (ioc *IOC) ReleaseBean(beanName string, bean interface{}) {
+	ioc.beanFactory.ReleaseBean(beanName, bean)
+}
+
+// Dependents 调用 bean 工厂 获取依赖 beanName 的所有 bean，回答"修改这个 bean 会影响哪些 bean"
+func (ioc *IOC) Dependents(beanName string) []string {
+	return ioc.beanFactory.Dependents(beanName)
+}
+
+// GetBeanDependents 调用 bean 工厂 获取依赖 beanName 的所有 bean，可以在 Rebind 前分析影响范围
+func (ioc *IOC) GetBeanDependents(beanName string) []string {
+	return ioc.beanFactory.GetBeanDependents(beanName)
+}
+
+// GetBeanDependencies 调用 bean 工厂 获取 bean 直接依赖的 beanName，不会创建 bean
+func (ioc *IOC) GetBeanDependencies(beanName string) []string {
+	return ioc.beanFactory.GetBeanDependencies(beanName)
+}
+
+// GetBeanDependenciesTransitive 调用 bean 工厂 按广度优先获取 bean 直接以及间接依赖的所有 beanName
+func (ioc *IOC) GetBeanDependenciesTransitive(beanName string) ([]string, error) {
+	return ioc.beanFactory.GetBeanDependenciesTransitive(beanName)
+}
+
+// InjectedDependencies 调用 bean 工厂 获取 bean 实际注入的依赖，key 为 field 名称，value 为注入的 beanName
+func (ioc *IOC) InjectedDependencies(beanName string) map[string]string {
+	return ioc.beanFactory.InjectedDependencies(beanName)
+}
+
+// GetCreationOrder 调用 bean 工厂 获取单例 bean 第一次创建的顺序
+func (ioc *IOC) GetCreationOrder() []string {
+	return ioc.beanFactory.GetCreationOrder()
+}
+
+// RegistrationOrder 调用 bean 工厂 按注册顺序获取已注册的 beanName
+func (ioc *IOC) RegistrationOrder() []string {
+	return ioc.beanFactory.RegistrationOrder()
+}
+
+// StartupDuration 调用 bean 工厂 获取每个 bean 的创建耗时
+func (ioc *IOC) StartupDuration() map[string]time.Duration {
+	return ioc.beanFactory.StartupDuration()
+}
+
+// Profile 调用 bean 工厂 执行 fn 并记录 fn 执行期间 bean 的创建树，例如：
+//
+//	tree, err := ioc.Profile(ioc.Refresh)
+func (ioc *IOC) Profile(fn func() error) (*CreationTree, error) {
+	return ioc.beanFactory.Profile(fn)
+}
+
+// ForEachSingleton 调用 bean 工厂 按创建顺序遍历已创建的单例 bean，fn 返回 error 时停止遍历
+func (ioc *IOC) ForEachSingleton(fn func(name string, bean interface{}) error) error {
+	return ioc.beanFactory.ForEachSingleton(fn)
+}
+
+// ForEachSingletonConcurrent 调用 bean 工厂 并发遍历已创建的单例 bean，返回第一个 error
+func (ioc *IOC) ForEachSingletonConcurrent(fn func(name string, bean interface{}) error) error {
+	return ioc.beanFactory.ForEachSingletonConcurrent(fn)
+}
+
+// GetBeanNameFor 调用 bean 工厂 根据单例 bean 实例反查 beanName，找不到时返回 false
+func (ioc *IOC) GetBeanNameFor(instance interface{}) (string, bool) {
+	return ioc.beanFactory.GetBeanNameFor(instance)
+}
+
+// GetBeansWhere 调用 bean 工厂 获取所有满足条件的 bean，pred 接收 beanName、限定符属性以及注册类型
+func (ioc *IOC) GetBeansWhere(pred func(name string, attrs map[string]string, t reflect.Type) bool) map[string]interface{} {
+	return ioc.beanFactory.GetBeansWhere(pred)
+}
+
+// GetAllBeanTypes 调用 bean 工厂 获取所有已注册 bean 的注册类型副本，可能是 *A 也可能是 A
+func (ioc *IOC) GetAllBeanTypes() map[string]reflect.Type {
+	return ioc.beanFactory.GetAllBeanTypes()
+}
+
+// GetRegisteredBeanCount 调用 bean 工厂 获取已注册的 bean 数量，用于监控以及健康检查
+func (ioc *IOC) GetRegisteredBeanCount() int {
+	return ioc.beanFactory.GetRegisteredBeanCount()
+}
+
+// GetCreatedSingletonCount 调用 bean 工厂 获取已经创建的单例 bean 数量，用于监控以及健康检查
+func (ioc *IOC) GetCreatedSingletonCount() int {
+	return ioc.beanFactory.GetCreatedSingletonCount()
+}
+
+// GetBeanHistogram 调用 bean 工厂 获取每个 bean 通过 GetBean 获取的次数，用于找出频繁获取、值得优化的 bean
+func (ioc *IOC) GetBeanHistogram() map[string]int {
+	return ioc.beanFactory.GetBeanHistogram()
+}
+
+// GetBeanHolder 调用 bean 工厂 获取 bean 以及 bean 的元信息，用于管理界面展示 bean 详情
+func (ioc *IOC) GetBeanHolder(beanName string) (*BeanHolder, error) {
+	return ioc.beanFactory.GetBeanHolder(beanName)
+}
+
+// FindBeanDefinitionByType 调用 bean 工厂 获取注册类型能够赋值给 t 的所有 beanName，按 beanName 排序
+func (ioc *IOC) FindBeanDefinitionByType(t reflect.Type) []string {
+	return ioc.beanFactory.FindBeanDefinitionByType(t)
+}
+
+// ListBeans 调用 bean 工厂 按条件列出已注册的 bean 信息
+func (ioc *IOC) ListBeans(filter BeanFilter) []BeanInfo {
+	return ioc.beanFactory.ListBeans(filter)
+}
+
+// ExportGraph 调用 bean 工厂 将 bean 的依赖图以 DOT 或者 Mermaid 格式写入 w
+func (ioc *IOC) ExportGraph(w io.Writer, format string) error {
+	return ioc.beanFactory.ExportGraph(w, format)
+}
+
+// Validate 调用 bean 工厂 静态校验 bean 的装配是否正确，可以在启动前或者测试中单独调用
+func (ioc *IOC) Validate() error {
+	return ioc.beanFactory.Validate()
+}
+
+// Refresh 调用 bean 工厂 刷新，提前创建所有单例 bean
+func (ioc *IOC) Refresh() error {
+	return ioc.beanFactory.Refresh()
+}
+
+// Rebind 调用 bean 工厂 使用新的 bean 定义替换已注册的 bean
+func (ioc *IOC) Rebind(def BeanDefinition) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.Rebind(def)
+}
+
+// DestroyBean 调用 bean 工厂 销毁单例 bean
+func (ioc *IOC) DestroyBean(beanName string) {
+	ioc.beanFactory.DestroyBean(beanName)
+}
+
+// Decorate 调用 bean 工厂 为 bean 注册装饰器，依赖方注入的是装饰后的 bean
+func (ioc *IOC) Decorate(beanName string, decorator Decorator) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.Decorate(beanName, decorator)
+}
+
+// Rewire 调用 bean 工厂 为已经创建的单例 bean 补充注入零值 field，用于运行时注册新 bean 之后填充可选依赖
+func (ioc *IOC) Rewire(beanName string) error {
+	return ioc.beanFactory.Rewire(beanName)
+}
+
+// EvictSingleton 调用 bean 工厂 丢弃缓存的单例 bean，下次获取时重新创建
+func (ioc *IOC) EvictSingleton(beanName string) error {
+	return ioc.beanFactory.EvictSingleton(beanName)
+}
+
+// Swap 调用 bean 工厂 使用新的实例替换单例 bean，已经通过 field 注入旧实例的 bean 不会感知替换
+func (ioc *IOC) Swap(beanName string, newInstance interface{}) error {
+	if ioc.frozen {
+		return ErrFrozen
+	}
+	return ioc.beanFactory.Swap(beanName, newInstance)
+}
+
+// Close 关闭 IOC，停止后台 goroutine 并销毁所有单例 bean
+func (ioc *IOC) Close() error {
+	if ioc.stop() {
+		ioc.beanFactory.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+			listener.OnCloseStart()
+		})
+		ioc.beanFactory.DestroySingletons()
+		ioc.beanFactory.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+			listener.OnCloseComplete()
+		})
+	}
+	return nil
+}
+
+// GracefulShutdown 等待进行中的 GetBean 调用结束后再关闭 IOC，超时返回 ctx.Err()，此时不会销毁单例 bean
+// 调用之后新的 GetBean 调用返回 ErrClosed，避免单例 bean 在销毁之后被重新创建
+func (ioc *IOC) GracefulShutdown(ctx context.Context) error {
+	if err := ioc.beanFactory.waitInflight(ctx); err != nil {
+		return err
+	}
+	return ioc.Close()
+}
+
+// stop 停止后台 goroutine，只有第一次调用返回 true
+func (ioc *IOC) stop() bool {
+	stopped := false
+	ioc.closeOnce.Do(func() {
+		close(ioc.done)
+		ioc.wg.Wait()
+		stopped = true
+	})
+	return stopped
+}
+
+// GetBeanFactory 获取 bean 工厂，用于调用更底层的方法，冻结的容器返回只读的 bean 工厂，注册、替换 bean 的方法返回 ErrFrozen
 func (ioc *IOC) GetBeanFactory() BeanFactory {
+	if ioc.frozen {
+		return frozenBeanFactory{ioc.beanFactory}
+	}
 	return ioc.beanFactory
 }
@@ -0,0 +1,282 @@
+package gioc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// recordingProcessor 记录经过初始化后处理的 bean
+type recordingProcessor struct {
+	initialized []string
+}
+
+func (p *recordingProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
+}
+
+func (p *recordingProcessor) processBeforeInstantiation(beanName string, t reflect.Type) interface{} {
+	return nil
+}
+
+func (p *recordingProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
+	p.initialized = append(p.initialized, beanName)
+	return bean
+}
+
+func (p *recordingProcessor) processBeforeDestruction(beanName string, bean interface{}) {}
+
+type processed struct {
+	x int
+}
+
+func TestRegisterBeanProcessor(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.RegisterBeanProcessor(NewClass("recorder", (*recordingProcessor)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("processed", (*processed)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	ioc.GetBean("processed")
+	recorder := ioc.GetBean("recorder").(*recordingProcessor)
+	if !reflect.DeepEqual(recorder.initialized, []string{"processed"}) {
+		t.Fatalf("processor saw %v, want [processed]", recorder.initialized)
+	}
+	// 通过 bean 工厂获取的是同一个容器中的 bean
+	if ioc.GetBeanFactory().GetBean("processed") != ioc.GetBean("processed") {
+		t.Fatal("bean factory returned a different singleton")
+	}
+}
+
+func TestRegisterBeanProcessorRejectsNonProcessor(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	if err := ioc.RegisterBeanProcessor(NewClass("processed", (*processed)(nil), Singleton)); err == nil {
+		t.Fatal("want error registering a bean that is not a processor")
+	}
+	if n := ioc.GetRegisteredBeanCount(); n != 0 {
+		t.Fatalf("registered %d beans, want 0", n)
+	}
+}
+
+var errFactoryFailed = errors.New("factory failed")
+
+type factoryProduct struct {
+	Dep *processed
+}
+
+type factoryConsumer struct {
+	Product *factoryProduct `di:"product"`
+}
+
+func TestRegisterFactory(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("processed", (*processed)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	err := ioc.RegisterFactory("product", Singleton, func(ioc *IOC) (interface{}, error) {
+		return &factoryProduct{Dep: ioc.GetBean("processed").(*processed)}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("consumer", (*factoryConsumer)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	product := ioc.GetBean("product").(*factoryProduct)
+	if product.Dep != ioc.GetBean("processed") {
+		t.Fatal("factory did not receive the container")
+	}
+	// 工厂函数创建的 bean 按 beanName 注入
+	if consumer := ioc.GetBean("consumer").(*factoryConsumer); consumer.Product != product {
+		t.Fatalf("got %p, want %p", consumer.Product, product)
+	}
+}
+
+func TestRegisterFactoryError(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	err := ioc.RegisterFactory("product", Singleton, func(ioc *IOC) (interface{}, error) {
+		return nil, errFactoryFailed
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("product"); !errors.Is(err, errFactoryFailed) || !strings.Contains(err.Error(), "product") {
+		t.Fatalf("got %v, want wrapped errFactoryFailed naming the bean", err)
+	}
+
+	ioc = NewIOC(WithFailFast(true))
+	if err := ioc.RegisterFactory("product", Singleton, func(ioc *IOC) (interface{}, error) {
+		return nil, errFactoryFailed
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, errFactoryFailed) {
+			t.Fatalf("got panic %v, want wrapped errFactoryFailed", err)
+		}
+	}()
+	ioc.GetBeanE("product")
+}
+
+func TestInjectInto(t *testing.T) {
+	ioc := NewIOC()
+	registerCaches(t, ioc)
+	if err := ioc.Register(NewClass("processed", (*processed)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	target := struct {
+		P     *processed `di:"processed"`
+		Cache Cache      `di:"@qualifier=mem"`
+		Skip  *processed
+	}{}
+	if err := ioc.InjectInto(&target); err != nil {
+		t.Fatal(err)
+	}
+	if target.P != ioc.GetBean("processed") || target.Cache != ioc.GetBean("memCache") || target.Skip != nil {
+		t.Fatalf("got %+v", target)
+	}
+	// 外部对象不会被注册
+	if n := ioc.GetRegisteredBeanCount(); n != 3 {
+		t.Fatalf("registered %d beans, want 3", n)
+	}
+	if err := ioc.InjectInto(target); err == nil {
+		t.Fatal("want error for a non-pointer target")
+	}
+}
+
+func TestGetOrRegister(t *testing.T) {
+	ioc := NewIOC()
+	first := ioc.GetOrRegister("processed", (*processed)(nil), Singleton)
+	second := ioc.GetOrRegister("processed", (*processed)(nil), Singleton)
+	if first == nil || first != second {
+		t.Fatalf("got %p and %p, want one singleton", first, second)
+	}
+	if n := ioc.GetRegisteredBeanCount(); n != 1 {
+		t.Fatalf("registered %d beans, want 1", n)
+	}
+}
+
+func TestGetOrRegisterConcurrent(t *testing.T) {
+	ioc := NewIOC()
+	const n = 8
+	beans := make([]interface{}, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			beans[i] = ioc.GetOrRegister("processed", (*processed)(nil), Singleton)
+		}(i)
+	}
+	wg.Wait()
+	for i := range beans {
+		if beans[i] == nil || beans[i] != beans[0] {
+			t.Fatalf("call %d got %p, want %p", i, beans[i], beans[0])
+		}
+	}
+	if n := ioc.GetRegisteredBeanCount(); n != 1 {
+		t.Fatalf("registered %d beans, want 1", n)
+	}
+}
+
+type namedDB struct {
+	dsn string
+}
+
+type replicatedStore struct {
+	primary, replica *namedDB
+}
+
+func TestNewFactoryClass(t *testing.T) {
+	ioc := NewIOC()
+	for name, dsn := range map[string]string{"primaryDB": "primary", "replicaDB": "replica"} {
+		dsn := dsn
+		class := NewClass(name, (*namedDB)(nil), Singleton).SetSupplier(func() interface{} {
+			return &namedDB{dsn: dsn}
+		})
+		if err := ioc.Register(class); err != nil {
+			t.Fatal(err)
+		}
+	}
+	factory := func(primary, replica *namedDB) *replicatedStore {
+		return &replicatedStore{primary: primary, replica: replica}
+	}
+	if err := ioc.Register(NewFactoryClass("store", factory, Singleton, []string{"primaryDB", "replicaDB"})); err != nil {
+		t.Fatal(err)
+	}
+	store := ioc.GetBean("store").(*replicatedStore)
+	if store.primary.dsn != "primary" || store.replica.dsn != "replica" {
+		t.Fatalf("got primary %q and replica %q", store.primary.dsn, store.replica.dsn)
+	}
+}
+
+func TestNewFactoryClassArgCountMismatch(t *testing.T) {
+	ioc := NewIOC()
+	factory := func(primary, replica *namedDB) *replicatedStore {
+		return &replicatedStore{primary: primary, replica: replica}
+	}
+	if err := ioc.Register(NewFactoryClass("store", factory, Singleton, []string{"primaryDB"})); err == nil {
+		t.Fatal("expected error when names do not match factory parameters")
+	}
+}
+
+// noopProcessor 不修改任何状态的 bean 处理器，可以在并发创建中使用
+type noopProcessor struct {
+	x int
+}
+
+func (p *noopProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
+}
+
+func (p *noopProcessor) processBeforeInstantiation(beanName string, t reflect.Type) interface{} {
+	return nil
+}
+
+func (p *noopProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
+	return bean
+}
+
+func (p *noopProcessor) processBeforeDestruction(beanName string, bean interface{}) {}
+
+func TestRegisterBeanProcessorConcurrentWithCreation(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("processed", (*processed)(nil), Prototype))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := ioc.GetBeanE("processed"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	// Refresh 自动发现处理器 bean，与 RegisterBeanProcessor 以及创建并发执行
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for j := 0; j < 20; j++ {
+			if err := ioc.Refresh(); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+	for i := 0; i < 20; i++ {
+		if err := ioc.RegisterBeanProcessor(NewClass(fmt.Sprintf("processor%d", i), (*noopProcessor)(nil), Singleton)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+	if n := len(ioc.beanFactory.(*BeanBeanFactory).getBeanProcessors()) - len(initBeanProcessors); n != 20 {
+		t.Fatalf("%d processors registered, want 20", n)
+	}
+}
@@ -0,0 +1,87 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// isKeyed 判断是否是键控作用域 bean
+func isKeyed(beanType BeanType) bool {
+	return beanType == Keyed
+}
+
+// isKeyedProvider 判断 t 是否为 func(key string) T 形式的键控提供者函数
+func isKeyedProvider(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 1 && t.In(0).Kind() == reflect.String && t.NumOut() == 1 && isBean(t.Out(0))
+}
+
+// GetBeanKeyed 根据 key 获取 bean，键控作用域的 bean 同一个 key 只会创建一次，不同 key 获取到不同的实例，
+// 适用于多租户等按运行时的值隔离实例的场景，其他作用域的 bean 与 GetBeanE 相同
+func (bc *BeanBeanFactory) GetBeanKeyed(key string, beanName string) (bean interface{}, err error) {
+	beanType := bc.getBeanType(beanName)
+	if beanType == Invalid {
+		return nil, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if !isKeyed(beanType) {
+		return bc.GetBeanE(beanName)
+	}
+	if err := bc.acquireCall(beanName); err != nil {
+		return nil, err
+	}
+	defer bc.releaseCall()
+	defer bc.recoverCreatePanic(&err)
+	bean = bc.doGetBeanKeyed(key, beanName)
+	return bean, nil
+}
+
+// doGetBeanKeyed 获取 key 对应的键控作用域 bean，创建失败时 panic
+func (bc *BeanBeanFactory) doGetBeanKeyed(key string, beanName string) interface{} {
+	return bc.keyedScope.get(key, beanName, func() interface{} {
+		return bc.createBean(context.Background(), beanName, Keyed, false)
+	})
+}
+
+// ReleaseKeyed 释放 key 对应的所有键控作用域 bean，按 beanName 排序调用销毁回调，例如租户下线时调用
+func (bc *BeanBeanFactory) ReleaseKeyed(key string) {
+	beans := bc.keyedScope.release(key)
+	beanNames := make([]string, 0, len(beans))
+	for beanName := range beans {
+		beanNames = append(beanNames, beanName)
+	}
+	sort.Strings(beanNames)
+	for _, beanName := range beanNames {
+		bc.destroySingleton(beanName, beans[beanName])
+	}
+}
+
+// injectKeyedProvider 为 func(key string) T 类型的 field 注入键控提供者函数，每次调用时按 key 获取 bean
+// 键控作用域的 bean 同一个 key 返回同一个实例，其他作用域的 bean 忽略 key；bean 不存在或者创建失败时 panic
+func (bc *BeanBeanFactory) injectKeyedProvider(fieldValue reflect.Value, field reflect.StructField, qualifier Qualifier) {
+	ft := field.Type
+	t := ft.Out(0)
+	beanType := getFieldBeanType(field)
+	provider := reflect.MakeFunc(ft, func(in []reflect.Value) []reflect.Value {
+		beanName, err := bc.resolveBeanName(field, t, qualifier)
+		if err != nil {
+			panic(err)
+		}
+		var bean interface{}
+		switch bc.getBeanType(beanName) {
+		case Invalid:
+			panic(fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered))
+		case Keyed:
+			bean = bc.doGetBeanKeyed(in[0].String(), beanName)
+		default:
+			bean = bc.getBeanWithScope(context.Background(), beanName, beanType, false)
+		}
+		bean = bc.adviseForInterface(beanName, t, bean)
+		beanValue := reflect.ValueOf(bean)
+		if !beanValue.IsValid() || !beanValue.Type().AssignableTo(t) {
+			panic(fmt.Errorf("bean %v of type %T is not assignable to %v", beanName, bean, t))
+		}
+		return []reflect.Value{beanValue}
+	})
+	fieldValue.Set(provider)
+}
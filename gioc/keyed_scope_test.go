@@ -0,0 +1,58 @@
+package gioc
+
+import (
+	"testing"
+)
+
+// tenantService 每个租户一个实例
+type tenantService struct {
+	x int
+}
+
+type tenantGateway struct {
+	Service func(tenant string) *tenantService `di:"tenantService"`
+}
+
+func newKeyedIOC() *IOC {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("tenantService", (*tenantService)(nil), Keyed),
+		NewClass("tenantGateway", (*tenantGateway)(nil), Singleton),
+	)
+	return ioc
+}
+
+func TestKeyedProvider(t *testing.T) {
+	ioc := newKeyedIOC()
+	gateway := ioc.GetBean("tenantGateway").(*tenantGateway)
+	a, b := gateway.Service("a"), gateway.Service("b")
+	if a == nil || b == nil || a == b {
+		t.Fatalf("different keys got %p and %p, want two instances", a, b)
+	}
+	if again := gateway.Service("a"); again != a {
+		t.Fatalf("same key got %p and %p, want one instance", a, again)
+	}
+	bean, err := ioc.GetBeanKeyed("a", "tenantService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bean != a {
+		t.Fatal("GetBeanKeyed should return the instance created by the provider")
+	}
+}
+
+func TestReleaseKeyed(t *testing.T) {
+	ioc := newKeyedIOC()
+	first, err := ioc.GetBeanKeyed("a", "tenantService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ioc.ReleaseKeyed("a")
+	second, err := ioc.GetBeanKeyed("a", "tenantService")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Fatal("released key should get a new instance")
+	}
+}
@@ -0,0 +1,131 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// LazyOption 变量注入注解中延迟注入的选项，例如 di:",lazy"
+const LazyOption = "lazy"
+
+// LazyProxyFactory 延迟代理工厂，target 第一次调用时才会从容器中获取目标 bean
+// golang 的反射无法在运行时动态生成带方法的类型（reflect.StructOf 不会提升嵌入接口的方法），因此接口的代理实现需要由用户提供，例如：
+//
+//	type lazyCache struct{ target func() interface{} }
+//	func (c *lazyCache) Get(key string) string { return c.target().(Cache).Get(key) }
+//
+// 延迟注入的依赖不参与循环依赖检查，关闭 allowEarlyReference 时，只要循环中有一个 field 是延迟注入，循环依赖就可以正常创建
+type LazyProxyFactory func(target func() interface{}) interface{}
+
+// RegisterLazyProxy 注册接口 iface 的延迟代理工厂，iface 格式为 (*Cache)(nil)
+func (bc *BeanBeanFactory) RegisterLazyProxy(iface interface{}, factory LazyProxyFactory) error {
+	t, ok := iface.(reflect.Type)
+	if !ok {
+		t = reflect.TypeOf(iface)
+	}
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Interface {
+		return fmt.Errorf("lazy proxy type %v is not an interface", t)
+	}
+	if factory == nil {
+		return fmt.Errorf("lazy proxy factory of %v is nil", t)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	bc.proxyMap[t] = factory
+	return nil
+}
+
+// injectLazy 为接口 field 注入延迟代理，目标 bean 在代理第一次被调用时才会创建，创建失败时每次调用都会重新创建
+func (bc *BeanBeanFactory) injectLazy(fieldValue reflect.Value, field reflect.StructField, qualifier Qualifier) {
+	ft := field.Type
+	if ft.Kind() != reflect.Interface {
+		panic(fmt.Errorf("lazy field %v must be an interface, got %v", field.Name, ft))
+	}
+	bc.mu.RLock()
+	factory := bc.proxyMap[ft]
+	bc.mu.RUnlock()
+	if factory == nil {
+		panic(fmt.Errorf("lazy field %v: no lazy proxy registered for %v, register one with RegisterLazyProxy", field.Name, ft))
+	}
+	// 只缓存获取成功的目标 bean，获取失败时 panic 交给调用方，下次调用代理时重新获取，
+	// 不使用 sync.Once，否则 panic 会消耗掉 once，之后的调用永远拿到 nil
+	var mu sync.Mutex
+	var target interface{}
+	proxy := factory(func() interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		if target == nil {
+			beanName, err := bc.getLazyBeanName(field, qualifier)
+			if err != nil {
+				panic(err)
+			}
+			target = bc.adviseForInterface(beanName, ft, bc.doGetBean(beanName, false))
+		}
+		return target
+	})
+	proxyValue := reflect.ValueOf(proxy)
+	if !proxyValue.IsValid() || !proxyValue.Type().AssignableTo(ft) {
+		panic(fmt.Errorf("lazy proxy of %v does not implement it", ft))
+	}
+	fieldValue.Set(proxyValue)
+}
+
+// isLazyFunc 判断 t 是否为 func() T 形式的延迟获取函数
+func isLazyFunc(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 1 && isBean(t.Out(0))
+}
+
+// injectLazyFunc 为 di:",lazy" 的 func() T 类型 field 注入闭包，每次调用闭包时才从当前 bean 工厂获取 bean
+// 与延迟代理不同，不需要为接口注册代理工厂；单例 bean 每次返回同一个实例，原型 bean 每次返回新的实例，bean 不存在或者创建失败时 panic
+func (bc *BeanBeanFactory) injectLazyFunc(fieldValue reflect.Value, field reflect.StructField, qualifier Qualifier) {
+	ft := field.Type
+	t := ft.Out(0)
+	beanType := getFieldBeanType(field)
+	fn := reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		beanName, err := bc.resolveBeanName(field, t, qualifier)
+		if err != nil {
+			panic(err)
+		}
+		if bc.getBeanType(beanName) == Invalid {
+			panic(fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered))
+		}
+		bean := bc.adviseForInterface(beanName, t, bc.getBeanWithScope(context.Background(), beanName, beanType, false))
+		beanValue := reflect.ValueOf(bean)
+		if !beanValue.IsValid() || !beanValue.Type().AssignableTo(t) {
+			panic(fmt.Errorf("bean %v of type %T is not assignable to %v", beanName, bean, t))
+		}
+		return []reflect.Value{beanValue}
+	})
+	fieldValue.Set(fn)
+}
+
+// getLazyBeanName 获取延迟注入 field 对应的 beanName
+func (bc *BeanBeanFactory) getLazyBeanName(field reflect.StructField, qualifier Qualifier) (string, error) {
+	return bc.resolveBeanName(field, field.Type, qualifier)
+}
+
+// resolveBeanName 获取 field 需要的类型为 t 的 beanName，优先级：限定符 > beanName 注解 > 唯一能够赋值给 t 的 bean
+func (bc *BeanBeanFactory) resolveBeanName(field reflect.StructField, t reflect.Type, qualifier Qualifier) (string, error) {
+	if !qualifier.isEmpty() {
+		return bc.getBeanNameWithQualifier(t, qualifier)
+	}
+	if beanName := getBeanName(field); beanName != "" {
+		return bc.canonicalName(beanName), nil
+	}
+	if bc.opts.strictByName {
+		return "", ErrNoBeanName
+	}
+	beanNames := bc.getBeanNamesAssignableTo(t)
+	if len(beanNames) == 0 {
+		return "", fmt.Errorf("no bean assignable to %v", t)
+	}
+	if len(beanNames) > 1 {
+		return "", fmt.Errorf("more than one bean assignable to %v: %v", t, beanNames)
+	}
+	return beanNames[0], nil
+}
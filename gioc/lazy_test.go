@@ -0,0 +1,104 @@
+package gioc
+
+import (
+	"errors"
+	"testing"
+)
+
+type Ponger interface {
+	Pong() string
+}
+
+// lazyPonger Ponger 的延迟代理，第一次调用时才获取目标 bean
+type lazyPonger struct {
+	target func() interface{}
+}
+
+func (p *lazyPonger) Pong() string { return p.target().(Ponger).Pong() }
+
+type pingA struct {
+	B Ponger `di:"pingB,lazy"`
+}
+
+func (*pingA) Name() string { return "a" }
+
+type pingB struct {
+	A *pingA `di:"pingA"`
+}
+
+func (b *pingB) Pong() string { return "pong from b, peer " + b.A.Name() }
+
+func TestLazyProxyResolvesCycleWithoutEarlyReference(t *testing.T) {
+	ioc := NewIOC(WithAllowEarlyReference(false))
+	created := 0
+	err := ioc.RegisterLazyProxy((*Ponger)(nil), func(target func() interface{}) interface{} {
+		created++
+		return &lazyPonger{target: target}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 循环中存在延迟注入的 field，注册时不会被当作循环依赖拒绝
+	ioc.MustRegisterAll(
+		NewClass("pingA", (*pingA)(nil), Singleton),
+		NewClass("pingB", (*pingB)(nil), Singleton),
+	)
+	a := ioc.GetBean("pingA").(*pingA)
+	if created != 1 {
+		t.Fatalf("lazy proxy created %d times, want 1", created)
+	}
+	// 目标 bean 在第一次调用时才创建
+	if n := ioc.GetCreatedSingletonCount(); n != 1 {
+		t.Fatalf("created %d singletons before first call, want 1", n)
+	}
+	if got := a.B.Pong(); got != "pong from b, peer a" {
+		t.Fatalf("got %q", got)
+	}
+	if b := ioc.GetBean("pingB").(*pingB); b.A != a {
+		t.Fatal("pingB was not wired to the same pingA")
+	}
+}
+
+type flakyPonger struct{}
+
+func (*flakyPonger) Pong() string { return "pong" }
+
+type flakyPingUser struct {
+	Ponger Ponger `di:"flakyPonger,lazy"`
+}
+
+// 目标 bean 第一次创建失败时，之后调用代理会重新创建，而不是永远拿到 nil
+func TestLazyProxyRetriesFailedCreation(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.RegisterLazyProxy((*Ponger)(nil), func(target func() interface{}) interface{} {
+		return &lazyPonger{target: target}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	attempts := 0
+	ioc.MustRegisterAll(
+		NewClass("flakyPonger", nil, Singleton).SetConstructor(func() (*flakyPonger, error) {
+			attempts++
+			if attempts == 1 {
+				return nil, errDialFailed
+			}
+			return &flakyPonger{}, nil
+		}),
+		NewClass("flakyPingUser", (*flakyPingUser)(nil), Singleton),
+	)
+	user := ioc.GetBean("flakyPingUser").(*flakyPingUser)
+	func() {
+		defer func() {
+			if err, ok := recover().(error); !ok || !errors.Is(err, errDialFailed) {
+				t.Fatalf("first call recovered %v, want errDialFailed", err)
+			}
+		}()
+		user.Ponger.Pong()
+	}()
+	if got := user.Ponger.Pong(); got != "pong" {
+		t.Fatalf("got %q after retry", got)
+	}
+	if attempts != 2 {
+		t.Fatalf("constructor called %d times, want 2", attempts)
+	}
+}
@@ -0,0 +1,121 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// DisposableBean 单例 bean 销毁时会调用 Destroy 释放资源
+type DisposableBean interface {
+	Destroy() error
+}
+
+// InitializingBean 属性注入完成后、initMethod 之前会调用 AfterPropertiesSet，ctx 为获取 bean 时传入的 context
+// 通过 GetBean 等不带 context 的方法获取时 ctx 为 context.Background()
+type InitializingBean interface {
+	AfterPropertiesSet(ctx context.Context) error
+}
+
+// checkLifecycleMethod 校验 bean 类型 t 上是否存在名为 methodName 的无参方法，返回值只能为空或者 error
+func checkLifecycleMethod(t reflect.Type, methodName string) error {
+	// 非 ptr 类型同时检查 ptr 的方法集，因为 bean 创建时持有的都是 ptr bean
+	mt := t
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface {
+		mt = reflect.PtrTo(t)
+	}
+	method, exist := mt.MethodByName(methodName)
+	if !exist {
+		return fmt.Errorf("method %v not found on %v", methodName, t)
+	}
+	ft := method.Type
+	// 非接口类型的方法第一个参数为 receiver
+	numIn := ft.NumIn()
+	if mt.Kind() != reflect.Interface {
+		numIn--
+	}
+	if numIn != 0 || ft.NumOut() > 1 || (ft.NumOut() == 1 && ft.Out(0) != errorType) {
+		return fmt.Errorf("method %v of %v must be func() or func() error", methodName, t)
+	}
+	return nil
+}
+
+// invokeLifecycleMethod 反射调用 bean 上名为 methodName 的无参方法
+func invokeLifecycleMethod(bean reflect.Value, methodName string) error {
+	method := bean.MethodByName(methodName)
+	if !method.IsValid() {
+		return fmt.Errorf("method %v not found on %v", methodName, bean.Type())
+	}
+	out := method.Call(nil)
+	if len(out) == 1 && !out[0].IsNil() {
+		return out[0].Interface().(error)
+	}
+	return nil
+}
+
+// invokeAfterPropertiesSet 属性注入完成后调用 InitializingBean.AfterPropertiesSet
+func (bc *BeanBeanFactory) invokeAfterPropertiesSet(ctx context.Context, beanName string, bean interface{}) {
+	initializing, ok := bean.(InitializingBean)
+	if !ok {
+		return
+	}
+	if err := initializing.AfterPropertiesSet(ctx); err != nil {
+		panic(fmt.Errorf("after properties set of bean %v failed: %w", beanName, err))
+	}
+}
+
+// invokeInitMethod 属性注入完成后调用 bean 的初始化方法
+func (bc *BeanBeanFactory) invokeInitMethod(beanName string, bean reflect.Value) {
+	class := bc.getClass(beanName)
+	if class == nil || class.initMethod == "" {
+		return
+	}
+	if err := invokeLifecycleMethod(bean, class.initMethod); err != nil {
+		panic(fmt.Errorf("init method %v of bean %v failed: %v", class.initMethod, beanName, err))
+	}
+}
+
+// processBeforeDestruction 销毁回调调用前依次调用所有 bean 处理器的 processBeforeDestruction
+// 处理器 panic 只打印错误，不影响其他处理器以及 bean 的销毁
+func (bc *BeanBeanFactory) processBeforeDestruction(beanName string, bean interface{}) {
+	for _, bp := range bc.getBeanProcessors() {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					fmt.Printf("bean processor %T processBeforeDestruction bean %v failed: %v\n", bp, beanName, r)
+				}
+			}()
+			bp.processBeforeDestruction(beanName, bean)
+		}()
+	}
+}
+
+// destroySingleton 调用单例 bean 的销毁回调，先调用 bean 处理器的 processBeforeDestruction，再调用 DisposableBean.Destroy，最后调用 destroyMethod
+// 销毁失败只打印错误，不影响其他 bean 的销毁
+func (bc *BeanBeanFactory) destroySingleton(beanName string, bean interface{}) {
+	bc.processBeforeDestruction(beanName, bean)
+	disposable, ok := bean.(DisposableBean)
+	if ok {
+		if err := disposable.Destroy(); err != nil {
+			fmt.Printf("destroy bean %v failed: %v\n", beanName, err)
+		}
+	}
+	class := bc.getClass(beanName)
+	if class == nil || class.destroyMethod == "" {
+		return
+	}
+	// destroyMethod 就是 Destroy 并且已经作为 DisposableBean 调用过了，不再重复调用
+	if ok && class.destroyMethod == "Destroy" {
+		return
+	}
+	beanV := reflect.ValueOf(bean)
+	// 非 ptr bean 需要取地址才能调用 ptr receiver 的方法
+	if beanV.Kind() != reflect.Ptr {
+		beanPtr := reflect.New(beanV.Type())
+		beanPtr.Elem().Set(beanV)
+		beanV = beanPtr
+	}
+	if err := invokeLifecycleMethod(beanV, class.destroyMethod); err != nil {
+		fmt.Printf("destroy method %v of bean %v failed: %v\n", class.destroyMethod, beanName, err)
+	}
+}
@@ -0,0 +1,30 @@
+package gioc
+
+// LifecyclePhaseListener 容器生命周期监听器，框架可以通过它在容器的各个阶段挂载自己的逻辑
+// 回调在调用方的 goroutine 中同步执行，执行时不持有容器的锁，可以在回调中访问容器
+type LifecyclePhaseListener interface {
+	// OnRefreshStart Refresh 开始时调用
+	OnRefreshStart()
+	// OnBeanRegistered bean 注册成功后调用，带注册条件的 bean 在 Refresh 中满足条件真正注册时调用
+	OnBeanRegistered(beanName string)
+	// OnRefreshComplete Refresh 成功完成后调用，Refresh 返回 error 时不会调用
+	OnRefreshComplete()
+	// OnCloseStart Close 销毁单例 bean 之前调用
+	OnCloseStart()
+	// OnCloseComplete Close 销毁所有单例 bean 之后调用
+	OnCloseComplete()
+}
+
+// WithLifecycleListener 添加容器生命周期监听器，多个监听器按添加顺序调用
+func WithLifecycleListener(listener LifecyclePhaseListener) Option {
+	return func(opts *Options) {
+		opts.lifecycleListeners = append(opts.lifecycleListeners, listener)
+	}
+}
+
+// fireLifecyclePhase 按添加顺序通知所有生命周期监听器
+func (bc *BeanBeanFactory) fireLifecyclePhase(fn func(listener LifecyclePhaseListener)) {
+	for _, listener := range bc.opts.lifecycleListeners {
+		fn(listener)
+	}
+}
@@ -0,0 +1,38 @@
+package gioc
+
+import (
+	"reflect"
+	"testing"
+)
+
+// phaseRecorder 记录生命周期监听器收到的阶段
+type phaseRecorder struct {
+	phases []string
+}
+
+func (r *phaseRecorder) OnRefreshStart()    { r.phases = append(r.phases, "refreshStart") }
+func (r *phaseRecorder) OnRefreshComplete() { r.phases = append(r.phases, "refreshComplete") }
+func (r *phaseRecorder) OnCloseStart()      { r.phases = append(r.phases, "closeStart") }
+func (r *phaseRecorder) OnCloseComplete()   { r.phases = append(r.phases, "closeComplete") }
+
+func (r *phaseRecorder) OnBeanRegistered(beanName string) {
+	r.phases = append(r.phases, "registered:"+beanName)
+}
+
+func TestLifecyclePhaseListener(t *testing.T) {
+	recorder := &phaseRecorder{}
+	ioc := NewIOC(WithLifecycleListener(recorder))
+	if err := ioc.Register(NewClass("counted", (*counted)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Refresh(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"registered:counted", "refreshStart", "refreshComplete", "closeStart", "closeComplete"}
+	if !reflect.DeepEqual(recorder.phases, want) {
+		t.Fatalf("phases %v, want %v", recorder.phases, want)
+	}
+}
@@ -0,0 +1,210 @@
+package gioc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type startable struct {
+	Dep     *scopedDep `di:"startDep"`
+	started bool
+	// Start 调用时依赖是否已经注入
+	depReady bool
+}
+
+func (s *startable) Start() error {
+	s.started = true
+	s.depReady = s.Dep != nil
+	return nil
+}
+
+type failingStart struct {
+	x int
+}
+
+func (*failingStart) Start() error { return errors.New("port in use") }
+
+func TestInitMethod(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	ioc.MustRegisterAll(
+		NewClass("startDep", (*scopedDep)(nil), Singleton),
+		NewClass("startable", (*startable)(nil), Singleton).SetInitMethod("Start"),
+		NewClass("failingStart", (*failingStart)(nil), Singleton).SetInitMethod("Start"),
+	)
+	s := ioc.GetBean("startable").(*startable)
+	if !s.started || !s.depReady {
+		t.Fatalf("started=%v depReady=%v, want init method called after population", s.started, s.depReady)
+	}
+	if _, err := ioc.GetBeanE("failingStart"); err == nil {
+		t.Fatal("want error returned by the init method")
+	}
+	// 注册时校验方法是否存在
+	if err := ioc.Register(NewClass("missingInit", (*failingStart)(nil), Singleton).SetInitMethod("Run")); err == nil {
+		t.Fatal("want error for a missing init method")
+	}
+}
+
+// destroyLog 记录销毁回调的调用顺序
+var destroyLog []string
+
+type shutdownDB struct {
+	x int
+}
+
+func (*shutdownDB) Shutdown() { destroyLog = append(destroyLog, "db.Shutdown") }
+
+type shutdownRepo struct {
+	DB *shutdownDB `di:"shutdownDB"`
+}
+
+func (*shutdownRepo) Destroy() error {
+	destroyLog = append(destroyLog, "repo.Destroy")
+	return nil
+}
+
+type shutdownService struct {
+	Repo *shutdownRepo `di:"shutdownRepo"`
+}
+
+func (*shutdownService) Stop() error {
+	destroyLog = append(destroyLog, "service.Stop")
+	return nil
+}
+
+func TestDestroyMethod(t *testing.T) {
+	destroyLog = nil
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("shutdownDB", (*shutdownDB)(nil), Singleton).SetDestroyMethod("Shutdown"),
+		NewClass("shutdownRepo", (*shutdownRepo)(nil), Singleton),
+		NewClass("shutdownService", (*shutdownService)(nil), Singleton).SetDestroyMethod("Stop"),
+	)
+	ioc.GetBean("shutdownService")
+	if err := ioc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 按创建的逆序销毁，destroyMethod 与 DisposableBean 交替进行
+	want := []string{"service.Stop", "repo.Destroy", "db.Shutdown"}
+	if !reflect.DeepEqual(destroyLog, want) {
+		t.Fatalf("got %v, want %v", destroyLog, want)
+	}
+}
+
+type prioritized struct {
+	Name string
+}
+
+func (p *prioritized) Destroy() error {
+	destroyLog = append(destroyLog, p.Name)
+	return nil
+}
+
+func TestDestroyPriority(t *testing.T) {
+	destroyLog = nil
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		// 最先创建，但是优先级最高，最先销毁
+		NewClass("logger", (*prioritized)(nil), Singleton).SetProperty("Name", "logger").SetDestroyPriority(10),
+		NewClass("db", (*prioritized)(nil), Singleton).SetProperty("Name", "db"),
+		NewClass("server", (*prioritized)(nil), Singleton).SetProperty("Name", "server"),
+		// 最后创建，但是优先级为负，最后销毁
+		NewClass("metrics", (*prioritized)(nil), Singleton).SetProperty("Name", "metrics").SetDestroyPriority(-1),
+	)
+	for _, name := range []string{"logger", "db", "server", "metrics"} {
+		ioc.GetBean(name)
+	}
+	if err := ioc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// 优先级相同的 db 和 server 按创建顺序的逆序销毁
+	want := []string{"logger", "server", "db", "metrics"}
+	if !reflect.DeepEqual(destroyLog, want) {
+		t.Fatalf("got %v, want %v", destroyLog, want)
+	}
+}
+
+type slowStartup struct{}
+
+func TestGracefulShutdownRejectsNewCalls(t *testing.T) {
+	entered, release := make(chan struct{}), make(chan struct{})
+	ioc := NewIOC(WithFailFast(false))
+	ioc.MustRegisterAll(
+		NewClass("slowStartup", (*slowStartup)(nil), Singleton).SetSupplier(func() interface{} {
+			close(entered)
+			<-release
+			return &slowStartup{}
+		}),
+		NewClass("prioritized", (*prioritized)(nil), Singleton),
+	)
+	got := make(chan error, 1)
+	go func() {
+		_, err := ioc.GetBeanE("slowStartup")
+		got <- err
+	}()
+	<-entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := ioc.GracefulShutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GracefulShutdown() = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := ioc.GetBeanE("prioritized"); !errors.Is(err, ErrClosed) {
+		t.Fatalf("GetBeanE() after shutdown = %v, want ErrClosed", err)
+	}
+
+	close(release)
+	if err := <-got; err != nil {
+		t.Fatalf("in-flight GetBeanE() = %v", err)
+	}
+	if err := ioc.GracefulShutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := ioc.GetCreatedSingletonCount(); n != 0 {
+		t.Fatalf("%v singletons left after shutdown", n)
+	}
+}
+
+func TestGracefulShutdownConcurrent(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	ioc.MustRegisterAll(
+		NewClass("prioritized", (*prioritized)(nil), Singleton),
+		NewClass("keyedPrioritized", (*prioritized)(nil), Keyed),
+	)
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				_, err := ioc.GetBeanE("prioritized")
+				if err == nil {
+					_, err = ioc.GetBeanKeyed("tenant", "keyedPrioritized")
+				}
+				if errors.Is(err, ErrClosed) {
+					return
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	if err := ioc.GracefulShutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	// 关闭之后单例不会被重新创建
+	if n := ioc.GetCreatedSingletonCount(); n != 0 {
+		t.Fatalf("%v singletons recreated after shutdown", n)
+	}
+}
@@ -0,0 +1,74 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// OptionalParam 可选的构造函数参数，容器中不存在对应类型的 bean 时为零值，例如：
+//
+//	func NewFoo(db *DB, cache OptionalParam[*Redis]) *Foo
+type OptionalParam[T any] struct {
+	value   T
+	present bool
+}
+
+// Value 返回注入的 bean，bean 不存在时返回 T 的零值
+func (o OptionalParam[T]) Value() T {
+	return o.value
+}
+
+// Present 判断 bean 是否存在
+func (o OptionalParam[T]) Present() bool {
+	return o.present
+}
+
+// set 设置注入的 bean
+func (o *OptionalParam[T]) set(bean interface{}) error {
+	// 类型化的 nil 视为 bean 不存在，避免接口类型的 T 得到非 nil 的接口
+	if isNilBean(bean) {
+		return nil
+	}
+	value, ok := bean.(T)
+	if !ok {
+		return fmt.Errorf("%T is not assignable to %v", bean, o.elemType())
+	}
+	o.value, o.present = value, true
+	return nil
+}
+
+// elemType 返回 T 的类型
+func (o *OptionalParam[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+// optionalParam 所有 OptionalParam 实例化类型的指针都实现了该接口，用于反射识别
+type optionalParam interface {
+	set(bean interface{}) error
+	elemType() reflect.Type
+}
+
+// optionalParamType optionalParam 的 reflect.Type
+var optionalParamType = reflect.TypeOf((*optionalParam)(nil)).Elem()
+
+// isOptionalParam 判断 t 是否为 OptionalParam
+func isOptionalParam(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(optionalParamType)
+}
+
+// newOptionalParam 创建类型为 t 的 OptionalParam，beanName 为空时表示 bean 不存在
+func (bc *BeanBeanFactory) newOptionalParam(ctx context.Context, t reflect.Type, beanName string) (reflect.Value, error) {
+	opt := reflect.New(t)
+	if beanName == "" || !bc.isRegistered(beanName) {
+		return opt.Elem(), nil
+	}
+	bean := bc.doGetBeanContext(dependencyContext(ctx), beanName, false)
+	if bean == nil {
+		return opt.Elem(), nil
+	}
+	if err := opt.Interface().(optionalParam).set(bean); err != nil {
+		return reflect.Value{}, err
+	}
+	return opt.Elem(), nil
+}
@@ -0,0 +1,93 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// ProcessorPhase bean 处理器的处理阶段
+type ProcessorPhase int
+
+const (
+	// PhasePropertyValues 属性注入阶段，fn 接收 ptr bean，返回值被忽略
+	PhasePropertyValues ProcessorPhase = iota + 1
+	// PhaseBeforeInstantiation 实例化前阶段，fn 接收的 bean 为 nil，返回不为 nil 时使用返回值作为 bean，不再创建
+	PhaseBeforeInstantiation
+	// PhaseAfterInitialization 初始化后阶段，fn 接收 bean，返回不为 nil 时替换 bean
+	PhaseAfterInitialization
+	// PhaseBeforeDestruction 单例 bean 销毁前阶段，fn 接收 bean，返回值被忽略
+	PhaseBeforeDestruction
+)
+
+// String
+func (p ProcessorPhase) String() string {
+	switch p {
+	case PhasePropertyValues:
+		return "processPropertyValues"
+	case PhaseBeforeInstantiation:
+		return "processBeforeInstantiation"
+	case PhaseAfterInitialization:
+		return "processAfterInitialization"
+	case PhaseBeforeDestruction:
+		return "processBeforeDestruction"
+	}
+	return fmt.Sprintf("ProcessorPhase(%d)", int(p))
+}
+
+// funcBeanProcessor 只处理一个阶段的函数式 bean 处理器，其他阶段为空操作
+type funcBeanProcessor struct {
+	phase ProcessorPhase
+	fn    func(beanName string, bean interface{}) interface{}
+}
+
+// processPropertyValues
+func (bp *funcBeanProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
+	if bp.phase != PhasePropertyValues {
+		return
+	}
+	if wrapBean.CanAddr() {
+		bp.fn(beanName, wrapBean.Addr().Interface())
+		return
+	}
+	bp.fn(beanName, wrapBean.Interface())
+}
+
+// processBeforeInstantiation
+func (bp *funcBeanProcessor) processBeforeInstantiation(beanName string, t reflect.Type) interface{} {
+	if bp.phase != PhaseBeforeInstantiation {
+		return nil
+	}
+	return bp.fn(beanName, nil)
+}
+
+// processAfterInitialization
+func (bp *funcBeanProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
+	if bp.phase != PhaseAfterInitialization {
+		return nil
+	}
+	return bp.fn(beanName, bean)
+}
+
+// processBeforeDestruction
+func (bp *funcBeanProcessor) processBeforeDestruction(beanName string, bean interface{}) {
+	if bp.phase != PhaseBeforeDestruction {
+		return
+	}
+	bp.fn(beanName, bean)
+}
+
+// RegisterBeanProcessorFunc 注册只处理 phase 阶段的函数式 bean 处理器，适用于日志、链路追踪、校验等只需要一个阶段的场景
+// 函数式处理器不是 bean，与 RegisterBeanProcessor 注册的处理器一起按注册顺序执行，作用于之后创建的所有 bean
+func (bc *BeanBeanFactory) RegisterBeanProcessorFunc(phase ProcessorPhase, fn func(beanName string, bean interface{}) interface{}) error {
+	if phase < PhasePropertyValues || phase > PhaseBeforeDestruction {
+		return fmt.Errorf("invalid processor phase %v", phase)
+	}
+	if fn == nil {
+		return fmt.Errorf("%v processor func is nil", phase)
+	}
+	bc.mu.Lock()
+	bc.beanProcessors = append(bc.beanProcessors, &funcBeanProcessor{phase: phase, fn: fn})
+	bc.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,33 @@
+package gioc
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestRegisterBeanProcessorFuncConcurrentWithCreation(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("counted", (*counted)(nil), Prototype))
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := ioc.GetBeanE("counted"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 50; i++ {
+		err := ioc.RegisterBeanProcessorFunc(PhaseAfterInitialization, func(beanName string, bean interface{}) interface{} {
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,134 @@
+package gioc
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrProfiling 已经存在进行中的 Profile
+var ErrProfiling = errors.New("profile is already in progress")
+
+// CreationTree bean 创建树的节点，Children 为创建该 bean 的过程中触发创建的依赖 bean
+type CreationTree struct {
+	// beanName，根节点为空
+	Name string
+	// 创建耗时，包含 Children 的创建耗时
+	Total time.Duration
+	// 自身的创建耗时，Total 减去 Children 的 Total
+	Self time.Duration
+	// 按创建顺序排列的子节点
+	Children []*CreationTree
+}
+
+// Folded 以 folded stacks 格式输出创建树，每行为 "a;b;c 自身耗时纳秒"，可以直接用于生成火焰图
+func (t *CreationTree) Folded() []string {
+	var lines []string
+	var walk func(node *CreationTree, prefix string)
+	walk = func(node *CreationTree, prefix string) {
+		stack := node.Name
+		if prefix != "" {
+			stack = prefix + ";" + node.Name
+		}
+		lines = append(lines, fmt.Sprintf("%v %d", stack, node.Self.Nanoseconds()))
+		for _, child := range node.Children {
+			walk(child, stack)
+		}
+	}
+	for _, child := range t.Children {
+		walk(child, "")
+	}
+	return lines
+}
+
+// String 以缩进的形式输出创建树
+func (t *CreationTree) String() string {
+	var sb strings.Builder
+	var walk func(node *CreationTree, depth int)
+	walk = func(node *CreationTree, depth int) {
+		fmt.Fprintf(&sb, "%v%v total=%v self=%v\n", strings.Repeat("  ", depth), node.Name, node.Total, node.Self)
+		for _, child := range node.Children {
+			walk(child, depth+1)
+		}
+	}
+	for _, child := range t.Children {
+		walk(child, 0)
+	}
+	return sb.String()
+}
+
+// creationProfiler 记录 bean 创建树，stack 为当前正在创建的 bean 路径，由 statMu 保护
+type creationProfiler struct {
+	root  *CreationTree
+	stack []*CreationTree
+}
+
+// Profile 执行 fn 并记录 fn 执行期间 bean 的创建树，fn 中可以调用 Refresh 或 GetBean
+// 根节点的 Children 为 fn 直接触发创建的 bean，已经创建的单例 bean 不会再次出现在树中
+// 创建树按嵌套顺序记录，fn 执行期间其他 goroutine 并发创建 bean 时树结构会交错，因此应在启动阶段单独使用
+// 同一时间只能存在一个进行中的 Profile，否则返回 ErrProfiling
+func (bc *BeanBeanFactory) Profile(fn func() error) (*CreationTree, error) {
+	p := &creationProfiler{root: &CreationTree{}}
+	bc.statMu.Lock()
+	if bc.profiler != nil {
+		bc.statMu.Unlock()
+		return nil, ErrProfiling
+	}
+	bc.profiler = p
+	bc.statMu.Unlock()
+	defer func() {
+		bc.statMu.Lock()
+		bc.profiler = nil
+		bc.statMu.Unlock()
+	}()
+	err := fn()
+	for _, child := range p.root.Children {
+		p.root.Total += child.Total
+	}
+	return p.root, err
+}
+
+// profileEnter 开始记录 bean 的创建，不存在进行中的 Profile 时返回 nil
+func (bc *BeanBeanFactory) profileEnter(beanName string) *CreationTree {
+	bc.statMu.Lock()
+	defer bc.statMu.Unlock()
+	p := bc.profiler
+	if p == nil {
+		return nil
+	}
+	node := &CreationTree{Name: beanName}
+	parent := p.root
+	if len(p.stack) > 0 {
+		parent = p.stack[len(p.stack)-1]
+	}
+	parent.Children = append(parent.Children, node)
+	p.stack = append(p.stack, node)
+	return node
+}
+
+// profileExit 结束记录 bean 的创建，创建失败的节点从创建树中移除
+func (bc *BeanBeanFactory) profileExit(node *CreationTree, d time.Duration, ok bool) {
+	if node == nil {
+		return
+	}
+	bc.statMu.Lock()
+	defer bc.statMu.Unlock()
+	p := bc.profiler
+	if p == nil || len(p.stack) == 0 || p.stack[len(p.stack)-1] != node {
+		return
+	}
+	p.stack = p.stack[:len(p.stack)-1]
+	if !ok {
+		parent := p.root
+		if len(p.stack) > 0 {
+			parent = p.stack[len(p.stack)-1]
+		}
+		parent.Children = parent.Children[:len(parent.Children)-1]
+		return
+	}
+	node.Total, node.Self = d, d
+	for _, child := range node.Children {
+		node.Self -= child.Total
+	}
+}
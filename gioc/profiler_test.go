@@ -0,0 +1,70 @@
+package gioc
+
+import (
+	"errors"
+	"testing"
+)
+
+// shape 输出只包含 beanName 的创建树结构，例如 app(repo(db),cache)
+func shape(node *CreationTree) string {
+	s := node.Name
+	if len(node.Children) == 0 {
+		return s
+	}
+	s += "("
+	for i, child := range node.Children {
+		if i > 0 {
+			s += ","
+		}
+		s += shape(child)
+	}
+	return s + ")"
+}
+
+func assertTimes(t *testing.T, node *CreationTree) {
+	t.Helper()
+	total := node.Self
+	for _, child := range node.Children {
+		assertTimes(t, child)
+		total += child.Total
+	}
+	if node.Self < 0 || total != node.Total {
+		t.Fatalf("node %v: self %v, total %v, children total do not add up", node.Name, node.Self, node.Total)
+	}
+}
+
+func TestProfile(t *testing.T) {
+	ioc := newTopoIOC()
+	tree, err := ioc.Profile(func() error {
+		_, err := ioc.GetBeanE("app")
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := shape(tree), "(app(repo(db),cache))"; got != want {
+		t.Fatalf("creation tree %v, want %v", got, want)
+	}
+	for _, node := range tree.Children {
+		assertTimes(t, node)
+	}
+	// 已经创建的单例 bean 不会再次出现在树中
+	tree, err = ioc.Profile(ioc.Refresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := shape(tree), "(zeta)"; got != want {
+		t.Fatalf("creation tree %v, want %v", got, want)
+	}
+}
+
+func TestProfileNested(t *testing.T) {
+	ioc := newTopoIOC()
+	_, err := ioc.Profile(func() error {
+		_, err := ioc.Profile(ioc.Refresh)
+		return err
+	})
+	if !errors.Is(err, ErrProfiling) {
+		t.Fatalf("got %v, want ErrProfiling", err)
+	}
+}
@@ -0,0 +1,47 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// checkProperty 校验 t 存在名为 name 的导出 field，并且 value 能够赋值给该 field，返回转换后的值
+func checkProperty(t reflect.Type, name string, value interface{}) (reflect.Value, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("property %v: %v is not a struct", name, t)
+	}
+	field, exist := t.FieldByName(name)
+	if !exist || field.PkgPath != "" {
+		return reflect.Value{}, fmt.Errorf("property %v: no exported field on %v", name, t)
+	}
+	v := reflect.ValueOf(value)
+	if !v.IsValid() {
+		return reflect.Zero(field.Type), nil
+	}
+	if v.Type().AssignableTo(field.Type) {
+		return v, nil
+	}
+	// 整数可以转换为 string，但得到的是对应的字符，不是期望的结果
+	if v.Type().ConvertibleTo(field.Type) && !(field.Type.Kind() == reflect.String && v.Kind() != reflect.String) {
+		return v.Convert(field.Type), nil
+	}
+	return reflect.Value{}, fmt.Errorf("property %v: %v is not assignable to %v", name, v.Type(), field.Type)
+}
+
+// applyProperties 将注册时设置的属性值赋给 bean 的同名 field，注册时已经校验过，这里失败直接 panic
+func (bc *BeanBeanFactory) applyProperties(beanName string, bean reflect.Value) {
+	class := bc.getClass(beanName)
+	if class == nil {
+		return
+	}
+	for name, value := range class.properties {
+		v, err := checkProperty(bean.Type(), name, value)
+		if err != nil {
+			panic(fmt.Errorf("bean %v: %v", beanName, err))
+		}
+		bean.FieldByName(name).Set(v)
+	}
+}
@@ -0,0 +1,43 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// PropertySource 属性源，提供字符串形式的配置属性
+type PropertySource interface {
+	// Property 获取属性值，属性不存在时返回 false
+	Property(key string) (string, bool)
+}
+
+// MapPropertySource 基于 map 的属性源
+type MapPropertySource map[string]string
+
+// Property 获取属性值
+func (m MapPropertySource) Property(key string) (string, bool) {
+	value, exist := m[key]
+	return value, exist
+}
+
+// isGuardSatisfied 判断 field 的 diif 条件是否满足，没有 diif 注解视为满足
+// 没有设置属性源或者属性不存在视为不满足，属性值不是合法的 bool 时返回 error
+func (bc *BeanBeanFactory) isGuardSatisfied(field reflect.StructField) (bool, error) {
+	key, exist := field.Tag.Lookup(GuardTag)
+	if !exist {
+		return true, nil
+	}
+	if bc.opts.propertySource == nil {
+		return false, nil
+	}
+	value, exist := bc.opts.propertySource.Property(key)
+	if !exist {
+		return false, nil
+	}
+	enabled, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("field %v: %v property %v: %v", field.Name, GuardTag, key, err)
+	}
+	return enabled, nil
+}
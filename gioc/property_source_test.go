@@ -0,0 +1,47 @@
+package gioc
+
+import "testing"
+
+type guardedService struct {
+	Cache *scopedDep `di:"guardedCache" diif:"caching.enabled"`
+}
+
+func getGuardedService(opts ...Option) (*guardedService, error) {
+	ioc := NewIOC(append(opts, WithFailFast(false))...)
+	ioc.MustRegisterAll(
+		NewClass("guardedCache", (*scopedDep)(nil), Singleton),
+		NewClass("guardedService", (*guardedService)(nil), Singleton),
+	)
+	bean, err := ioc.GetBeanE("guardedService")
+	if err != nil {
+		return nil, err
+	}
+	return bean.(*guardedService), nil
+}
+
+func TestGuardTag(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		opts     []Option
+		injected bool
+	}{
+		{"enabled", []Option{WithPropertySource(MapPropertySource{"caching.enabled": "true"})}, true},
+		{"disabled", []Option{WithPropertySource(MapPropertySource{"caching.enabled": "false"})}, false},
+		{"missing property", []Option{WithPropertySource(MapPropertySource{})}, false},
+		{"no property source", nil, false},
+	} {
+		service, err := getGuardedService(tc.opts...)
+		if err != nil {
+			t.Fatalf("%v: %v", tc.name, err)
+		}
+		if injected := service.Cache != nil; injected != tc.injected {
+			t.Fatalf("%v: injected = %v, want %v", tc.name, injected, tc.injected)
+		}
+	}
+}
+
+func TestGuardTagInvalidValue(t *testing.T) {
+	if _, err := getGuardedService(WithPropertySource(MapPropertySource{"caching.enabled": "maybe"})); err == nil {
+		t.Fatal("want error for a guard property that is not a bool")
+	}
+}
@@ -0,0 +1,55 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// isErrorProvider 判断 t 是否为 func() (T, error) 形式的提供者函数
+func isErrorProvider(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() == 2 && t.Out(1) == errorType && isBean(t.Out(0))
+}
+
+// isProviderFunc 判断 field 是否为调用时才获取 bean 的函数：func() (T, error)、func(key string) T 以及 di:",lazy" 的 func() T
+func isProviderFunc(field reflect.StructField) bool {
+	t := field.Type
+	return isErrorProvider(t) || isKeyedProvider(t) || (hasAutowiredOption(field, LazyOption) && isLazyFunc(t))
+}
+
+// injectErrorProvider 为 func() (T, error) 类型的 field 注入提供者函数
+// 每次调用提供者时才从容器中获取 bean，bean 不存在或者创建失败时以 error 返回，不受 failFast 影响
+func (bc *BeanBeanFactory) injectErrorProvider(fieldValue reflect.Value, field reflect.StructField, qualifier Qualifier) {
+	ft := field.Type
+	beanType := getFieldBeanType(field)
+	provider := reflect.MakeFunc(ft, func([]reflect.Value) []reflect.Value {
+		bean, err := bc.provide(field, ft.Out(0), beanType, qualifier)
+		if err != nil {
+			return []reflect.Value{reflect.Zero(ft.Out(0)), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{bean, reflect.Zero(errorType)}
+	})
+	fieldValue.Set(provider)
+}
+
+// provide 获取提供者函数返回的 bean，bean 创建过程中的 panic 会转换为 error
+func (bc *BeanBeanFactory) provide(field reflect.StructField, t reflect.Type, beanType BeanType, qualifier Qualifier) (value reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = toError(r)
+		}
+	}()
+	beanName, err := bc.resolveBeanName(field, t, qualifier)
+	if err != nil {
+		return value, err
+	}
+	if bc.getBeanType(beanName) == Invalid {
+		return value, fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	bean := bc.adviseForInterface(beanName, t, bc.getBeanWithScope(context.Background(), beanName, beanType, false))
+	beanValue := reflect.ValueOf(bean)
+	if !beanValue.IsValid() || !beanValue.Type().AssignableTo(t) {
+		return value, fmt.Errorf("bean %v of type %T is not assignable to %v", beanName, bean, t)
+	}
+	return beanValue, nil
+}
@@ -0,0 +1,73 @@
+package gioc
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+var errDialFailed = errors.New("dial failed")
+
+type providedConn struct {
+	x int
+}
+
+type providerUser struct {
+	Conn func() (*providedConn, error) `di:"providedConn"`
+}
+
+func newProviderIOC(t *testing.T, conn *Class) *providerUser {
+	t.Helper()
+	ioc := NewIOC()
+	ioc.MustRegisterAll(conn, NewClass("providerUser", (*providerUser)(nil), Singleton))
+	user, err := ioc.GetBeanE("providerUser")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user.(*providerUser)
+}
+
+func TestErrorProviderReturnsCreationError(t *testing.T) {
+	conn := NewClass("providedConn", nil, Singleton).SetConstructor(func() (*providedConn, error) {
+		return nil, errDialFailed
+	})
+	// 提供者在调用时才创建 bean，获取 providerUser 不受影响
+	user := newProviderIOC(t, conn)
+	bean, err := user.Conn()
+	if !errors.Is(err, errDialFailed) {
+		t.Fatalf("got %v, want errDialFailed", err)
+	}
+	if bean != nil {
+		t.Fatalf("got %v, want nil bean on error", bean)
+	}
+}
+
+func TestErrorProviderRecoversPanic(t *testing.T) {
+	conn := NewClass("providedConn", nil, Singleton).SetConstructor(func() *providedConn {
+		panic("connection pool exhausted")
+	})
+	user := newProviderIOC(t, conn)
+	if _, err := user.Conn(); err == nil || !strings.Contains(err.Error(), "connection pool exhausted") {
+		t.Fatalf("got %v, want the recovered panic as error", err)
+	}
+}
+
+func TestErrorProviderNotRegistered(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("providerUser", (*providerUser)(nil), Singleton))
+	user := ioc.GetBean("providerUser").(*providerUser)
+	if _, err := user.Conn(); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("got %v, want ErrNotRegistered", err)
+	}
+}
+
+func TestErrorProviderSuccess(t *testing.T) {
+	user := newProviderIOC(t, NewClass("providedConn", (*providedConn)(nil), Singleton))
+	first, err := user.Conn()
+	if err != nil || first == nil {
+		t.Fatalf("got %v, %v", first, err)
+	}
+	if second, _ := user.Conn(); second != first {
+		t.Fatal("provider should return the singleton on every call")
+	}
+}
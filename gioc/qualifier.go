@@ -0,0 +1,70 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Qualifier 限定符，同一类型存在多个 bean 时用于选择注入哪一个，类似 Spring 的 @Qualifier
+// 注入点的限定符与 bean 的限定符 Name 相同，并且注入点的每个 Attributes 在 bean 的限定符中都存在且相等时匹配
+type Qualifier struct {
+	// 限定符名称
+	Name string
+	// 限定符属性
+	Attributes map[string]string
+}
+
+// isEmpty 判断限定符是否为空
+func (q Qualifier) isEmpty() bool {
+	return q.Name == "" && len(q.Attributes) == 0
+}
+
+// matches 判断注入点的限定符 q 是否匹配 bean 的限定符 target
+func (q Qualifier) matches(target Qualifier) bool {
+	if q.Name != target.Name {
+		return false
+	}
+	for key, value := range q.Attributes {
+		if v, exist := target.Attributes[key]; !exist || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// copy 复制限定符，避免注册后外部修改 Attributes
+func (q Qualifier) copy() Qualifier {
+	if q.Attributes == nil {
+		return q
+	}
+	attrs := make(map[string]string, len(q.Attributes))
+	for key, value := range q.Attributes {
+		attrs[key] = value
+	}
+	return Qualifier{Name: q.Name, Attributes: attrs}
+}
+
+// String
+func (q Qualifier) String() string {
+	if len(q.Attributes) == 0 {
+		return q.Name
+	}
+	attrs := make([]string, 0, len(q.Attributes))
+	for key, value := range q.Attributes {
+		attrs = append(attrs, key+"="+value)
+	}
+	sort.Strings(attrs)
+	return fmt.Sprintf("%v{%v}", q.Name, strings.Join(attrs, ","))
+}
+
+// getInjectQualifier 获取 bean 的 field 注入时使用的限定符，通过 Class.SetFieldQualifier 设置的限定符优先于 di 注解
+func (bc *BeanBeanFactory) getInjectQualifier(beanName string, field reflect.StructField) Qualifier {
+	if class := bc.getClass(beanName); class != nil {
+		if q, exist := class.fieldQualifiers[field.Name]; exist {
+			return q
+		}
+	}
+	return Qualifier{Name: getFieldQualifier(field)}
+}
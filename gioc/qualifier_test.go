@@ -0,0 +1,60 @@
+package gioc
+
+import "testing"
+
+// Cache 测试用的缓存接口，存在内存和 redis 两种实现
+type Cache interface {
+	Name() string
+}
+
+type memCache struct {
+	x int
+}
+
+func (*memCache) Name() string { return "mem" }
+
+type redisCache struct {
+	x int
+}
+
+func (*redisCache) Name() string { return "redis" }
+
+type cacheUser struct {
+	Redis Cache `di:"@qualifier=redis"`
+	Mem   Cache `di:"@qualifier=mem"`
+}
+
+func registerCaches(t *testing.T, ioc *IOC) {
+	t.Helper()
+	ioc.MustRegisterAll(
+		NewClass("memCache", (*memCache)(nil), Singleton).SetQualifier("mem"),
+		NewClass("redisCache", (*redisCache)(nil), Singleton).SetQualifier("redis"),
+	)
+}
+
+func TestInterfaceFieldWithQualifier(t *testing.T) {
+	ioc := NewIOC()
+	registerCaches(t, ioc)
+	if err := ioc.Register(NewClass("cacheUser", (*cacheUser)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	user := ioc.GetBean("cacheUser").(*cacheUser)
+	if user.Redis != ioc.GetBean("redisCache") || user.Mem != ioc.GetBean("memCache") {
+		t.Fatalf("got redis=%v mem=%v", user.Redis, user.Mem)
+	}
+}
+
+type unknownCacheUser struct {
+	C Cache `di:"@qualifier=memcached"`
+}
+
+func TestInterfaceFieldWithUnknownQualifier(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	registerCaches(t, ioc)
+	if err := ioc.Register(NewClass("unknownCacheUser", (*unknownCacheUser)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("unknownCacheUser"); err == nil {
+		t.Fatal("want error when no implementation carries the qualifier")
+	}
+}
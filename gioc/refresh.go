@@ -0,0 +1,81 @@
+package gioc
+
+import (
+	"reflect"
+)
+
+// beanProcessorType BeanProcessor 接口类型
+var beanProcessorType = reflect.TypeOf((*BeanProcessor)(nil)).Elem()
+
+// Refresh 刷新 bean 工厂，在所有 bean 注册完成后调用
+// 1、对设置了注册条件的 bean 求值，注册满足条件的 bean
+// 2、开启 autoDiscoverProcessors 时，将实现了 BeanProcessor 的 bean 注册为 bean 处理器，排在手动注册的处理器之后
+// 3、按依赖关系拓扑排序后提前创建所有单例 bean，被依赖的 bean 先创建，创建失败返回 error
+// Refresh 本身就有 error 返回值，因此不受 failFast 影响，创建过程中的 panic 总是转换为 error
+func (bc *BeanBeanFactory) Refresh() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = toError(r)
+		}
+	}()
+	bc.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+		listener.OnRefreshStart()
+	})
+	if err := bc.registerConditionalClasses(); err != nil {
+		return err
+	}
+	if bc.opts.autoDiscoverProcessors {
+		if err := bc.discoverProcessors(); err != nil {
+			return err
+		}
+	}
+	// 按依赖关系排序后创建，创建顺序与注册顺序、map 遍历顺序无关
+	for _, beanName := range bc.sortByDependency(bc.getBeanNames()) {
+		if !isSingleton(bc.getBeanType(beanName)) {
+			continue
+		}
+		if _, err := bc.getBeanE(beanName); err != nil {
+			return err
+		}
+	}
+	bc.fireLifecyclePhase(func(listener LifecyclePhaseListener) {
+		listener.OnRefreshComplete()
+	})
+	return nil
+}
+
+// discoverProcessors 自动发现实现了 BeanProcessor 的 bean 并注册为 bean 处理器
+func (bc *BeanBeanFactory) discoverProcessors() error {
+	for _, beanName := range bc.getBeanNames() {
+		bc.mu.RLock()
+		_, exist := bc.bpNames[beanName]
+		t := bc.tMap[beanName]
+		bc.mu.RUnlock()
+		if exist {
+			continue
+		}
+		// bean 创建时持有的是 ptr bean，因此非 ptr 类型需要检查 ptr 的方法集
+		if !t.Implements(beanProcessorType) && (t.Kind() == reflect.Ptr || !reflect.PtrTo(t).Implements(beanProcessorType)) {
+			continue
+		}
+		bean, err := bc.getBeanE(beanName)
+		if err != nil {
+			return err
+		}
+		if bp, ok := bean.(BeanProcessor); ok {
+			bc.addBeanProcessor(beanName, bp)
+		}
+	}
+	return nil
+}
+
+// addBeanProcessor 添加 bean 处理器，同一个 beanName 只会添加一次，例如并发 Refresh 时同时发现了同一个处理器
+func (bc *BeanBeanFactory) addBeanProcessor(beanName string, bp BeanProcessor) {
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	if _, exist := bc.bpNames[beanName]; exist {
+		return
+	}
+	bc.bpNames[beanName] = struct{}{}
+	bc.beanProcessors = append(bc.beanProcessors, bp)
+}
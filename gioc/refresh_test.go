@@ -0,0 +1,84 @@
+package gioc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type topoDB struct {
+	x int
+}
+
+type topoRepo struct {
+	DB *topoDB `di:"db"`
+}
+
+type topoCache struct {
+	x int
+}
+
+type topoApp struct {
+	Repo  *topoRepo  `di:"repo"`
+	Cache *topoCache `di:"cache"`
+}
+
+type topoZeta struct {
+	x int
+}
+
+// newTopoIOC 按与依赖关系相反的顺序注册 bean
+func newTopoIOC() *IOC {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("zeta", (*topoZeta)(nil), Singleton),
+		NewClass("app", (*topoApp)(nil), Singleton),
+		NewClass("repo", (*topoRepo)(nil), Singleton),
+		NewClass("cache", (*topoCache)(nil), Singleton),
+		NewClass("db", (*topoDB)(nil), Singleton),
+	)
+	return ioc
+}
+
+func TestRefreshCreationOrderIsStable(t *testing.T) {
+	want := []string{"db", "repo", "cache", "app", "zeta"}
+	for run := 0; run < 10; run++ {
+		ioc := newTopoIOC()
+		if err := ioc.Refresh(); err != nil {
+			t.Fatal(err)
+		}
+		if got := ioc.GetCreationOrder(); !reflect.DeepEqual(got, want) {
+			t.Fatalf("run %d: creation order %v, want %v", run, got, want)
+		}
+	}
+}
+
+func TestSortByDependency(t *testing.T) {
+	ioc := newTopoIOC()
+	got := ioc.beanFactory.(*BeanBeanFactory).sortByDependency([]string{"zeta", "app", "cache", "db", "repo"})
+	if want := []string{"zeta", "db", "repo", "cache", "app"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("sortByDependency = %v, want %v", got, want)
+	}
+}
+
+func newFailingDBClass() *Class {
+	return NewClass("db", nil, Singleton).SetConstructor(func() (*topoDB, error) {
+		return nil, errDialFailed
+	})
+}
+
+// Refresh 有 error 返回值，默认的 failFast 下创建失败也返回 error 而不是 panic
+func TestRefreshReturnsCreationError(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(newFailingDBClass(), NewClass("repo", (*topoRepo)(nil), Singleton))
+	if err := ioc.Refresh(); !errors.Is(err, errDialFailed) {
+		t.Fatalf("Refresh() = %v, want errDialFailed", err)
+	}
+}
+
+func TestBuildReturnsCreationError(t *testing.T) {
+	ioc, err := NewBuilder().RegisterClass(newFailingDBClass()).Build()
+	if !errors.Is(err, errDialFailed) || ioc != nil {
+		t.Fatalf("Build() = %v, %v, want errDialFailed", ioc, err)
+	}
+}
@@ -0,0 +1,40 @@
+package gioc
+
+import (
+	"context"
+	"sync"
+)
+
+// RequestScope 绑定一个 context 的作用域，作用域内每个 bean 只会创建一次，一般每个请求注册一个
+type RequestScope struct {
+	ctx   context.Context
+	mu    sync.Mutex
+	beans map[string]interface{}
+}
+
+// NewRequestScope 实例化一个绑定 ctx 的作用域
+func NewRequestScope(ctx context.Context) *RequestScope {
+	return &RequestScope{
+		ctx:   ctx,
+		beans: map[string]interface{}{},
+	}
+}
+
+// Get 获取作用域内的 bean，不存在时调用 objectFactory 创建
+func (rs *RequestScope) Get(beanName string, objectFactory func() interface{}) interface{} {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if bean, exist := rs.beans[beanName]; exist {
+		return bean
+	}
+	bean := objectFactory()
+	if bean != nil {
+		rs.beans[beanName] = bean
+	}
+	return bean
+}
+
+// Context 返回作用域绑定的 context
+func (rs *RequestScope) Context() context.Context {
+	return rs.ctx
+}
@@ -0,0 +1,41 @@
+package gioc
+
+import (
+	"context"
+	"testing"
+)
+
+type requestKey struct{}
+
+type requestHandler struct {
+	Ctx context.Context `scopectx:""`
+}
+
+func TestRequestScopeContextInjection(t *testing.T) {
+	ctx := context.WithValue(context.Background(), requestKey{}, "req-1")
+	ioc := NewIOC()
+	if err := ioc.RegisterScope("request", NewRequestScope(ctx)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Register(NewClass("requestHandler", (*requestHandler)(nil), "request")); err != nil {
+		t.Fatal(err)
+	}
+	handler := ioc.GetBean("requestHandler").(*requestHandler)
+	if handler.Ctx != ctx || handler.Ctx.Value(requestKey{}) != "req-1" {
+		t.Fatalf("got %v, want the scope context", handler.Ctx)
+	}
+	// 作用域内只创建一次
+	if ioc.GetBean("requestHandler") != handler {
+		t.Fatal("request scoped bean created twice in one scope")
+	}
+}
+
+func TestScopeContextOutsideContextScope(t *testing.T) {
+	ioc := NewIOC(WithFailFast(false))
+	if err := ioc.Register(NewClass("requestHandler", (*requestHandler)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ioc.GetBeanE("requestHandler"); err == nil {
+		t.Fatal("want error injecting scope context outside a context-bound scope")
+	}
+}
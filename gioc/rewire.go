@@ -0,0 +1,34 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// Rewire 为已经创建的单例 bean 重新执行属性注入，只注入值为零值的 field，已经设置过的 field 保持不变
+// 适用于运行时注册了新的 bean 之后，补充注入之前不存在的可选依赖，例如插件系统
+// bean 必须是 ptr 结构体，注入过程中其他 goroutine 同时读取 bean 的 field 需要调用方自行同步
+func (bc *BeanBeanFactory) Rewire(beanName string) (err error) {
+	beanType := bc.getBeanType(beanName)
+	if beanType == Invalid {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if !isSingleton(beanType) {
+		return fmt.Errorf("bean %v is not a singleton", beanName)
+	}
+	bc.smu.RLock()
+	bean := bc.singletonMap[beanName]
+	bc.smu.RUnlock()
+	if bean == nil {
+		return fmt.Errorf("bean %v has not been created", beanName)
+	}
+	beanV := reflect.ValueOf(bean)
+	if beanV.Kind() != reflect.Ptr || beanV.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("bean %v of type %T can not be rewired", beanName, bean)
+	}
+	defer bc.recoverCreatePanic(&err)
+	bp := &PopulateBeanProcessor{bc: bc}
+	bp.populate(context.Background(), beanName, beanV.Elem(), beanV.Elem().Type(), true)
+	return nil
+}
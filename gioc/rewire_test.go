@@ -0,0 +1,55 @@
+package gioc
+
+import "testing"
+
+type plugin struct {
+	x int
+}
+
+type pluginHost struct {
+	Plugin   *plugin    `di:"plugin,optional"`
+	Existing *scopedDep `di:"existingDep"`
+}
+
+func TestRewire(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegisterAll(
+		NewClass("existingDep", (*scopedDep)(nil), Singleton),
+		NewClass("pluginHost", (*pluginHost)(nil), Singleton),
+	)
+	host := ioc.GetBean("pluginHost").(*pluginHost)
+	if host.Plugin != nil {
+		t.Fatal("optional dependency injected before it was registered")
+	}
+	// 已经设置过的 field 保持不变
+	custom := &scopedDep{}
+	host.Existing = custom
+	if err := ioc.Register(NewClass("plugin", (*plugin)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Rewire("pluginHost"); err != nil {
+		t.Fatal(err)
+	}
+	if host.Plugin == nil || host.Plugin != ioc.GetBean("plugin") {
+		t.Fatalf("got %p, want the plugin registered later", host.Plugin)
+	}
+	if host.Existing != custom {
+		t.Fatal("Rewire overwrote a field that was already set")
+	}
+	if deps := ioc.InjectedDependencies("pluginHost"); deps["Plugin"] != "plugin" {
+		t.Fatalf("got injected dependencies %v, want Plugin recorded", deps)
+	}
+}
+
+func TestRewireErrors(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("pluginHost", (*pluginHost)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioc.Rewire("pluginHost"); err == nil {
+		t.Fatal("want error rewiring a singleton that has not been created")
+	}
+	if err := ioc.Rewire("absent"); err == nil {
+		t.Fatal("want error rewiring an unregistered bean")
+	}
+}
@@ -0,0 +1,51 @@
+package gioc
+
+import "fmt"
+
+// setScopeOptions 修改作用域的可选参数
+type setScopeOptions struct {
+	// 单例 bean 已经创建时先销毁再修改
+	force bool
+}
+
+// SetScopeOption 修改作用域的可选参数
+type SetScopeOption func(*setScopeOptions)
+
+// ForceSetScope 单例 bean 已经创建时先调用 DestroyBean 销毁，再修改作用域，不返回 ErrAlreadyCreated
+func ForceSetScope() SetScopeOption {
+	return func(opts *setScopeOptions) {
+		opts.force = true
+	}
+}
+
+// SetBeanScope 修改已注册 bean 的作用域，用于加载配置后将部分 bean 从原型切换为单例等启动场景
+// 之后获取 bean 时按新的作用域交给对应的容器处理；已经注入到其他 bean 中的实例不受影响
+// 单例 bean 已经创建时返回 ErrAlreadyCreated，使用 ForceSetScope 时会先销毁已创建的单例
+func (bc *BeanBeanFactory) SetBeanScope(beanName string, newScope BeanType, opts ...SetScopeOption) error {
+	options := &setScopeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	beanName = bc.canonicalName(beanName)
+	bc.smu.RLock()
+	_, created := bc.singletonMap[beanName]
+	bc.smu.RUnlock()
+	if created {
+		if !options.force {
+			return fmt.Errorf("bean %v: %w", beanName, ErrAlreadyCreated)
+		}
+		bc.DestroyBean(beanName)
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+	class, exist := bc.cMap[beanName]
+	if !exist {
+		return fmt.Errorf("bean %v: %w", beanName, ErrNotRegistered)
+	}
+	if !bc.isValidBeanType(newScope) {
+		return fmt.Errorf("bean %v: beanType %v is not a registered scope", beanName, newScope)
+	}
+	bc.btMap[beanName] = newScope
+	class.beanType = newScope
+	return nil
+}
@@ -0,0 +1,52 @@
+package gioc
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"reflect"
+)
+
+// WithSnapshotFallbackHandler 设置 GetBeanSnapshot 退化为浅拷贝时的回调，err 为深拷贝失败的原因，用于记录日志或者告警
+func WithSnapshotFallbackHandler(handler func(beanName string, err error)) Option {
+	return func(opts *Options) {
+		opts.snapshotFallback = handler
+	}
+}
+
+// GetBeanSnapshot 获取 bean 的深拷贝，外部修改拷贝不会影响容器中共享的 bean，bean 不存在返回 nil
+// 使用 encoding/gob 序列化再反序列化，bean 可以实现 gob.GobEncoder、gob.GobDecoder 自定义拷贝逻辑；
+// gob 只会拷贝导出的 field，无法使用 gob 的类型（例如没有导出 field、含有未注册的接口值）退化为浅拷贝，
+// 并将 gob 的错误交给 WithSnapshotFallbackHandler 设置的回调
+func (bc *BeanBeanFactory) GetBeanSnapshot(beanName string) interface{} {
+	bean := bc.GetBean(beanName)
+	if bean == nil {
+		return nil
+	}
+	v := reflect.ValueOf(bean)
+	t := v.Type()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	snapshot := reflect.New(t)
+	if err := deepCopy(bean, snapshot.Interface()); err != nil {
+		if bc.opts.snapshotFallback != nil {
+			bc.opts.snapshotFallback(beanName, fmt.Errorf("snapshot of bean %v falls back to shallow copy: %w", beanName, err))
+		}
+		snapshot = reflect.New(t)
+		snapshot.Elem().Set(reflect.Indirect(v))
+	}
+	if v.Kind() == reflect.Ptr {
+		return snapshot.Interface()
+	}
+	return snapshot.Elem().Interface()
+}
+
+// deepCopy 通过 gob 将 src 拷贝到 dst 指向的变量
+func deepCopy(src, dst interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+	return gob.NewDecoder(&buf).Decode(dst)
+}
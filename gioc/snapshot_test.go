@@ -0,0 +1,40 @@
+package gioc
+
+import "testing"
+
+type snapshotConfig struct {
+	Hosts []string
+}
+
+// opaqueState 没有导出 field，无法使用 gob 拷贝
+type opaqueState struct {
+	hits int
+}
+
+func TestGetBeanSnapshot(t *testing.T) {
+	ioc := NewIOC()
+	ioc.MustRegister(NewClass("snapshotConfig", (*snapshotConfig)(nil), Singleton).SetSupplier(func() interface{} {
+		return &snapshotConfig{Hosts: []string{"a", "b"}}
+	}))
+	snapshot := ioc.GetBeanSnapshot("snapshotConfig").(*snapshotConfig)
+	snapshot.Hosts[0] = "changed"
+	if got := ioc.GetBean("snapshotConfig").(*snapshotConfig).Hosts[0]; got != "a" {
+		t.Fatalf("modifying the snapshot changed the bean: %v", got)
+	}
+}
+
+func TestGetBeanSnapshotFallback(t *testing.T) {
+	var reported []string
+	ioc := NewIOC(WithSnapshotFallbackHandler(func(beanName string, err error) {
+		reported = append(reported, beanName)
+	}))
+	ioc.MustRegister(NewClass("opaqueState", (*opaqueState)(nil), Singleton))
+	ioc.GetBean("opaqueState").(*opaqueState).hits = 3
+	snapshot := ioc.GetBeanSnapshot("opaqueState").(*opaqueState)
+	if snapshot == ioc.GetBean("opaqueState") || snapshot.hits != 3 {
+		t.Fatalf("got %+v, want a shallow copy", snapshot)
+	}
+	if len(reported) != 1 || reported[0] != "opaqueState" {
+		t.Fatalf("fallback reported %v, want [opaqueState]", reported)
+	}
+}
@@ -0,0 +1,52 @@
+package gioc
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Resettable 线程作用域的 bean 归还到对象池时会调用 Reset 清理本次使用的状态
+type Resettable interface {
+	Reset()
+}
+
+// isThread 判断是否是线程作用域 bean
+func isThread(beanType BeanType) bool {
+	return beanType == Thread
+}
+
+// BorrowBean 从对象池借用线程作用域的 bean，借用期间由调用方独占，使用完毕后调用 returnFunc 归还
+// 对象池中的 bean 只会注入一次，归还时如果实现了 Resettable 会先调用 Reset，适合缓冲区、解析器等创建代价较高、可以复用的对象
+// 对象池基于 sync.Pool，空闲的 bean 可能被 GC 回收；其他作用域的 bean 按原有语义获取，returnFunc 不做任何事情
+// 获取失败时打印错误并返回 nil
+func (bc *BeanBeanFactory) BorrowBean(beanName string) (interface{}, func()) {
+	noop := func() {}
+	if !isThread(bc.getBeanType(beanName)) {
+		return bc.GetBean(beanName), noop
+	}
+	pool := bc.getThreadPool(beanName)
+	bean := pool.Get()
+	if bean == nil {
+		var err error
+		bean, err = bc.GetBeanE(beanName)
+		if err != nil {
+			fmt.Println(err)
+			return nil, noop
+		}
+	}
+	var once sync.Once
+	return bean, func() {
+		once.Do(func() {
+			if resettable, ok := bean.(Resettable); ok {
+				resettable.Reset()
+			}
+			pool.Put(bean)
+		})
+	}
+}
+
+// getThreadPool 获取线程作用域 bean 的对象池
+func (bc *BeanBeanFactory) getThreadPool(beanName string) *sync.Pool {
+	pool, _ := bc.threadPools.LoadOrStore(beanName, &sync.Pool{})
+	return pool.(*sync.Pool)
+}
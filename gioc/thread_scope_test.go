@@ -0,0 +1,57 @@
+package gioc
+
+import (
+	"testing"
+)
+
+// pooledBuffer 可以复用的缓冲区，归还时清空
+type pooledBuffer struct {
+	data   []byte
+	resets *int
+}
+
+func (b *pooledBuffer) Reset() {
+	b.data = b.data[:0]
+	*b.resets++
+}
+
+func TestBorrowBean(t *testing.T) {
+	ioc := NewIOC()
+	resets := 0
+	class := NewClass("buffer", (*pooledBuffer)(nil), Thread).SetSupplier(func() interface{} {
+		return &pooledBuffer{resets: &resets}
+	})
+	if err := ioc.Register(class); err != nil {
+		t.Fatal(err)
+	}
+	bean, returnFunc := ioc.BorrowBean("buffer")
+	buffer := bean.(*pooledBuffer)
+	buffer.data = append(buffer.data, "dirty"...)
+	returnFunc()
+	// 重复归还只生效一次
+	returnFunc()
+	if resets != 1 {
+		t.Fatalf("Reset called %d times, want 1", resets)
+	}
+	if len(buffer.data) != 0 {
+		t.Fatalf("returned buffer not reset: %q", buffer.data)
+	}
+	// sync.Pool 不保证一定复用，复用与否借到的 bean 都必须是干净的
+	bean, returnFunc = ioc.BorrowBean("buffer")
+	defer returnFunc()
+	if again := bean.(*pooledBuffer); len(again.data) != 0 {
+		t.Fatalf("re-borrowed buffer not reset: %q", again.data)
+	}
+}
+
+func TestBorrowBeanNonThreadScope(t *testing.T) {
+	ioc := NewIOC()
+	if err := ioc.Register(NewClass("counted", (*counted)(nil), Singleton)); err != nil {
+		t.Fatal(err)
+	}
+	bean, returnFunc := ioc.BorrowBean("counted")
+	returnFunc()
+	if bean != ioc.GetBean("counted") {
+		t.Fatal("BorrowBean on a singleton should return the singleton")
+	}
+}
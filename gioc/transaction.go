@@ -0,0 +1,166 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// RunWithTransaction 在子容器中执行 fn，子容器复制当前容器的 bean 定义、作用域、延迟代理和 bean 处理器，
+// 并共享当前容器已经创建好的单例 bean，fn 中新创建的单例只存在于子容器
+// fn 返回 error、panic 或 ctx 结束时回滚：关闭子容器，销毁子容器中新创建的单例 bean
+// 执行成功时提交：子容器中新创建的单例 bean 提升到当前容器，当前容器已经存在同名单例或没有对应单例定义的 bean 会被销毁
+// 自定义作用域的 Scope 实例由父子容器共享，作用域中缓存的 bean 不参与回滚
+func (ioc *IOC) RunWithTransaction(ctx context.Context, fn func(tx *IOC) error) (err error) {
+	if err = ctx.Err(); err != nil {
+		return err
+	}
+	parent, ok := ioc.beanFactory.(*BeanBeanFactory)
+	if !ok {
+		return fmt.Errorf("bean factory %T does not support transaction", ioc.beanFactory)
+	}
+	child, err := parent.fork()
+	if err != nil {
+		return err
+	}
+	tx := &IOC{
+		beanFactory: child,
+		done:        make(chan struct{}),
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = toError(r)
+		}
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			_ = tx.Close()
+			return
+		}
+		tx.stop()
+		parent.promoteSingletons(child)
+	}()
+	return fn(tx)
+}
+
+// fork 复制一个子 bean 工厂，子工厂与当前工厂共享已经创建好的单例 bean 和用户注册的 bean 处理器
+func (bc *BeanBeanFactory) fork() (*BeanBeanFactory, error) {
+	opts := *bc.opts
+	child := NewBeanFactory().(*BeanBeanFactory)
+	child.opts = &opts
+
+	bc.mu.RLock()
+	for beanType, c := range bc.scMap {
+		if sc, ok := c.(*ScopeContainer); ok {
+			child.scMap[beanType] = NewScopeContainer(child, beanType, sc.scope)
+		}
+	}
+	for t, factory := range bc.proxyMap {
+		child.proxyMap[t] = factory
+	}
+	for alias, beanName := range bc.aliasMap {
+		child.aliasMap[alias] = beanName
+	}
+	for beanName, decorators := range bc.decoratorMap {
+		child.decoratorMap[beanName] = append([]Decorator(nil), decorators...)
+	}
+	for beanName, advices := range bc.adviceMap {
+		child.adviceMap[beanName] = map[reflect.Type][]InterfaceAdvice{}
+		for t, list := range advices {
+			child.adviceMap[beanName][t] = append([]InterfaceAdvice(nil), list...)
+		}
+	}
+	for name, t := range bc.typeRegistry {
+		child.typeRegistry[name] = t
+	}
+	child.conditionalClasses = append(child.conditionalClasses, bc.conditionalClasses...)
+	// 按注册顺序复制 bean 定义，保证子容器的注册顺序与当前容器一致
+	classes := make([]*Class, 0, len(bc.cMap))
+	for _, class := range bc.cMap {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool {
+		return classes[i].order < classes[j].order
+	})
+	child.mu.Lock()
+	for _, class := range classes {
+		c := *class
+		if err := child.doRegister(&c); err != nil {
+			child.mu.Unlock()
+			bc.mu.RUnlock()
+			return nil, err
+		}
+	}
+	child.mu.Unlock()
+	for beanName, deps := range bc.injectedMap {
+		child.injectedMap[beanName] = deps
+	}
+	// 前 len(initBeanProcessors) 个是绑定当前工厂的内置处理器，子工厂已经有自己的内置处理器
+	child.beanProcessors = append(child.beanProcessors, bc.beanProcessors[len(initBeanProcessors):]...)
+	for beanName := range bc.bpNames {
+		child.bpNames[beanName] = struct{}{}
+	}
+	bc.mu.RUnlock()
+
+	// 共享的单例不记录到 creationOrder，子容器关闭时不会销毁它们
+	bc.smu.RLock()
+	for beanName, bean := range bc.singletonMap {
+		if bean != nil {
+			child.singletonMap[beanName] = bean
+		}
+	}
+	bc.smu.RUnlock()
+	return child, nil
+}
+
+// promoteSingletons 将子工厂中新创建的单例 bean 按创建顺序提升到当前工厂
+func (bc *BeanBeanFactory) promoteSingletons(child *BeanBeanFactory) {
+	child.smu.RLock()
+	beanNames := append([]string(nil), child.creationOrder...)
+	beans := make(map[string]interface{}, len(beanNames))
+	for _, beanName := range beanNames {
+		beans[beanName] = child.singletonMap[beanName]
+	}
+	child.smu.RUnlock()
+
+	var discarded []string
+	for _, beanName := range beanNames {
+		bean := beans[beanName]
+		if bean == nil {
+			continue
+		}
+		if !bc.promoteSingleton(beanName, bean) {
+			discarded = append(discarded, beanName)
+			continue
+		}
+		if deps := child.InjectedDependencies(beanName); len(deps) > 0 {
+			bc.setInjectedDependencies(beanName, deps)
+		}
+	}
+	// 与创建顺序相反的顺序销毁没有提升的 bean
+	for i := len(discarded) - 1; i >= 0; i-- {
+		child.destroySingleton(discarded[i], beans[discarded[i]])
+	}
+}
+
+// promoteSingleton 当前工厂存在单例定义并且还没有创建该单例时，将 bean 加入单例缓存
+func (bc *BeanBeanFactory) promoteSingleton(beanName string, bean interface{}) bool {
+	if bc.getBeanType(beanName) != Singleton {
+		return false
+	}
+	bc.smu.Lock()
+	defer bc.smu.Unlock()
+	if bc.singletonMap[beanName] != nil {
+		return false
+	}
+	bc.singletonMap[beanName] = bean
+	bc.createdAtMap[beanName] = time.Now()
+	if _, exist := bc.createdMap[beanName]; !exist {
+		bc.createdMap[beanName] = struct{}{}
+		bc.creationOrder = append(bc.creationOrder, beanName)
+	}
+	return true
+}
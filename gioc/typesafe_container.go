@@ -0,0 +1,65 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// TypeSafeContainer 只存取 T 类型 bean 的类型安全容器，例如所有的 http.Handler 路由、所有的定时任务
+// 内部委托给 IOC，获取 bean 不需要再做类型断言
+type TypeSafeContainer[T any] struct {
+	ioc *IOC
+}
+
+// NewTypeSafeContainer 实例化一个类型安全容器，ioc 为 nil 时使用一个新的 IOC
+func NewTypeSafeContainer[T any](ioc *IOC) *TypeSafeContainer[T] {
+	if ioc == nil {
+		ioc = NewIOC()
+	}
+	return &TypeSafeContainer[T]{
+		ioc: ioc,
+	}
+}
+
+// Register 注册一个 T 类型的 bean，impl 与 NewClass 的 i 相同，只使用其动态类型，例如 (*MyHandler)(nil)
+func (c *TypeSafeContainer[T]) Register(beanName string, impl T, scope BeanType) error {
+	if reflect.TypeOf(impl) == nil {
+		return fmt.Errorf("bean %v: impl of %v has no concrete type", beanName, c.elemType())
+	}
+	return c.ioc.Register(NewClass(beanName, impl, scope))
+}
+
+// GetBean 获取 T 类型的 bean，bean 没有注册返回 ErrNotRegistered，类型不是 T 返回 ErrTypeMismatch
+func (c *TypeSafeContainer[T]) GetBean(beanName string) (T, error) {
+	var zero T
+	bean, err := c.ioc.GetBeanE(beanName)
+	if err != nil {
+		return zero, err
+	}
+	value, ok := bean.(T)
+	if !ok {
+		return zero, fmt.Errorf("bean %v of type %T is not %v: %w", beanName, bean, c.elemType(), ErrTypeMismatch)
+	}
+	return value, nil
+}
+
+// GetAll 按 beanName 排序获取所有 T 类型的 bean，获取失败的 bean 会被跳过
+func (c *TypeSafeContainer[T]) GetAll() []T {
+	var beans []T
+	for _, beanName := range c.ioc.FindBeanDefinitionByType(c.elemType()) {
+		if bean, err := c.GetBean(beanName); err == nil {
+			beans = append(beans, bean)
+		}
+	}
+	return beans
+}
+
+// IOC 返回内部委托的 IOC
+func (c *TypeSafeContainer[T]) IOC() *IOC {
+	return c.ioc
+}
+
+// elemType 返回 T 的类型
+func (c *TypeSafeContainer[T]) elemType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
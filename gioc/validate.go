@@ -0,0 +1,168 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ValidationError 一条装配校验错误
+type ValidationError struct {
+	// 出错的 bean
+	BeanName string
+	// 出错的注入点，field 名称或者构造函数参数，bean 级别的错误为空
+	Field string
+	// 错误信息
+	Message string
+}
+
+// Error
+func (e ValidationError) Error() string {
+	if e.Field == "" {
+		return fmt.Sprintf("bean %v: %v", e.BeanName, e.Message)
+	}
+	return fmt.Sprintf("bean %v field %v: %v", e.BeanName, e.Field, e.Message)
+}
+
+// ValidationReport 装配校验报告
+type ValidationReport struct {
+	Errors []ValidationError
+}
+
+// Error
+func (r *ValidationReport) Error() string {
+	msgs := make([]string, 0, len(r.Errors))
+	for _, err := range r.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%v validation errors:\n%v", len(r.Errors), strings.Join(msgs, "\n"))
+}
+
+// add 添加一条校验错误
+func (r *ValidationReport) add(beanName, field, format string, args ...interface{}) {
+	r.Errors = append(r.Errors, ValidationError{
+		BeanName: beanName,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Validate 静态校验所有已注册 bean 的装配是否正确，不会创建任何 bean
+// 校验内容：di 注入点能否解析到 bean、类型是否兼容、构造函数参数是否满足、不允许循环依赖时是否存在循环依赖
+// 校验通过返回 nil，否则返回 *ValidationReport
+func (bc *BeanBeanFactory) Validate() error {
+	report := &ValidationReport{}
+	beanNames := bc.getBeanNames()
+	graph := map[string][]string{}
+	for _, beanName := range beanNames {
+		class := bc.getClass(beanName)
+		// 没有指定参数的构造函数，参数在创建时按类型自动获取
+		if class.constructor != nil && len(class.constructorArgs) > 0 {
+			if err := checkConstructorArgs(reflect.TypeOf(class.constructor), len(class.constructorArgs)); err != nil {
+				report.add(beanName, "", "%v", err)
+			}
+		}
+		deps, errs := bc.getDependencies(beanName)
+		for _, err := range errs {
+			report.add(beanName, "", "%v", err)
+		}
+		for _, dep := range deps {
+			bc.mu.RLock()
+			depType, exist := bc.tMap[dep.beanName]
+			bc.mu.RUnlock()
+			if !exist {
+				// field 依赖的 bean 未注册时会按 field 类型自动注册，只有接口和构造函数参数无法自动注册
+				if !dep.optional && (dep.isArg || dep.t.Kind() == reflect.Interface) {
+					report.add(beanName, dep.source, "bean %v is not registered", dep.beanName)
+				}
+				continue
+			}
+			if !isInjectable(depType, dep.t) {
+				report.add(beanName, dep.source, "bean %v of type %v is not assignable to %v", dep.beanName, depType, dep.t)
+			}
+			if !dep.lazy {
+				graph[beanName] = append(graph[beanName], dep.beanName)
+			}
+		}
+	}
+	// 不允许暴露早期对象时，任何循环依赖都会在创建时失败
+	if !bc.isAllowEarlyReference() {
+		for _, cycle := range findCycles(beanNames, graph) {
+			report.add(cycle[0], "", "circular dependency: %v", strings.Join(cycle, " -> "))
+		}
+	}
+	if len(report.Errors) > 0 {
+		return report
+	}
+	return nil
+}
+
+// isInjectable 判断 bean 类型 beanType 能否注入到类型为 target 的注入点
+func isInjectable(beanType, target reflect.Type) bool {
+	// 注册类型为 interface{} 的 bean（例如工厂函数创建的 bean）在创建前无法确定实际类型
+	if beanType == anyType {
+		return true
+	}
+	switch target.Kind() {
+	case reflect.Ptr:
+		return beanType == target
+	case reflect.Struct, reflect.Map, reflect.Slice:
+		// 结构体、map 以及 slice bean 注册的类型为 ptr，注入时取值
+		return beanType == target || beanType == reflect.PtrTo(target)
+	default:
+		return beanType.AssignableTo(target)
+	}
+}
+
+// findCycles 通过 DFS 查找依赖图中的所有环，每个环只返回一次，首尾为同一个 beanName
+func findCycles(beanNames []string, graph map[string][]string) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var path []string
+	var cycles [][]string
+	var dfs func(beanName string)
+	dfs = func(beanName string) {
+		state[beanName] = visiting
+		path = append(path, beanName)
+		for _, dep := range graph[beanName] {
+			switch state[dep] {
+			case unvisited:
+				dfs(dep)
+			case visiting:
+				// 从 path 中截取出环
+				for i := len(path) - 1; i >= 0; i-- {
+					if path[i] == dep {
+						cycle := append([]string{}, path[i:]...)
+						cycles = append(cycles, append(cycle, dep))
+						break
+					}
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		state[beanName] = visited
+	}
+	for _, beanName := range beanNames {
+		if state[beanName] == unvisited {
+			dfs(beanName)
+		}
+	}
+	return cycles
+}
+
+// getBeanNames 获取所有已注册的 beanName，按 beanName 排序
+func (bc *BeanBeanFactory) getBeanNames() []string {
+	bc.mu.RLock()
+	defer bc.mu.RUnlock()
+	beanNames := make([]string, 0, len(bc.btMap))
+	for beanName := range bc.btMap {
+		beanNames = append(beanNames, beanName)
+	}
+	sort.Strings(beanNames)
+	return beanNames
+}
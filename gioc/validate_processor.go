@@ -0,0 +1,70 @@
+package gioc
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// ValidateTag 注入完成后校验 field 的注解，目前只支持 validate:"required"，其他规则忽略，便于与其他校验库共用注解
+const ValidateTag = "validate"
+
+// RequiredRule field 在注入完成后不能为零值
+const RequiredRule = "required"
+
+// ValidateBeanProcessor 校验 bean 处理器，开启 WithValidation 后在注入完成后校验 bean 的 field
+// validate:"required" 的 field 仍然为零值（nil 指针、空字符串、0 等）时 bean 创建失败，错误为 ValidationError
+type ValidateBeanProcessor struct {
+	bc *BeanBeanFactory
+}
+
+// NewValidateBeanProcessor
+func NewValidateBeanProcessor(bc *BeanBeanFactory) BeanProcessor {
+	return &ValidateBeanProcessor{bc: bc}
+}
+
+// processPropertyValues 排在注入处理器之后，此时属性和依赖都已经注入完毕
+func (bp *ValidateBeanProcessor) processPropertyValues(ctx context.Context, beanName string, wrapBean reflect.Value, t reflect.Type) {
+	if !bp.bc.opts.validation {
+		return
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup(ValidateTag)
+		if !ok || !hasValidateRule(tag, RequiredRule) {
+			continue
+		}
+		if wrapBean.Field(i).IsZero() {
+			panic(ValidationError{
+				BeanName: beanName,
+				Field:    field.Name,
+				Message:  fmt.Sprintf("%v:%q field is zero after injection", ValidateTag, tag),
+			})
+		}
+	}
+}
+
+// processBeforeInstantiation
+func (bp *ValidateBeanProcessor) processBeforeInstantiation(beanName string, t reflect.Type) interface{} {
+	return nil
+}
+
+// processAfterInitialization
+func (bp *ValidateBeanProcessor) processAfterInitialization(beanName string, bean interface{}, t reflect.Type) interface{} {
+	return nil
+}
+
+// processBeforeDestruction
+func (bp *ValidateBeanProcessor) processBeforeDestruction(beanName string, bean interface{}) {
+}
+
+// hasValidateRule 判断逗号分隔的校验注解中是否存在某个规则
+func hasValidateRule(tag, rule string) bool {
+	for _, r := range strings.Split(tag, ",") {
+		if strings.TrimSpace(r) == rule {
+			return true
+		}
+	}
+	return false
+}
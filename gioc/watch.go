@@ -0,0 +1,102 @@
+package gioc
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ConfigSource bean 定义来源，WatchConfig 会定时从中加载 bean 定义
+type ConfigSource interface {
+	// LoadDefinitions 加载所有 bean 定义
+	LoadDefinitions() ([]BeanDefinition, error)
+}
+
+// WatchConfig 每隔 interval 从 source 加载一次 bean 定义，并与当前注册的定义对比：
+// 新增的定义会注册，发生变化的定义会 Rebind，从 source 中移除的定义会被注销并销毁
+// 只会处理由 source 加载的 bean，其他方式注册的 bean 不受影响，调用 Close 停止
+// 冻结的容器不会被修改，打印 ErrFrozen 后直接返回
+func (ioc *IOC) WatchConfig(source ConfigSource, interval time.Duration) {
+	if ioc.frozen {
+		fmt.Println(fmt.Errorf("watch config: %w", ErrFrozen))
+		return
+	}
+	ioc.wg.Add(1)
+	go func() {
+		defer ioc.wg.Done()
+		// 由 source 加载的 beanName
+		watched := map[string]struct{}{}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			ioc.syncDefinitions(source, watched)
+			select {
+			case <-ioc.done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// syncDefinitions 同步一次 source 中的 bean 定义
+func (ioc *IOC) syncDefinitions(source ConfigSource, watched map[string]struct{}) {
+	if ioc.frozen {
+		fmt.Println(fmt.Errorf("watch config: %w", ErrFrozen))
+		return
+	}
+	defs, err := source.LoadDefinitions()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	bc, ok := ioc.beanFactory.(*BeanBeanFactory)
+	if !ok {
+		return
+	}
+	loaded := map[string]struct{}{}
+	for _, def := range defs {
+		loaded[def.Name] = struct{}{}
+		cur, exist := bc.getBeanDefinition(def.Name)
+		if exist && isSameBeanDefinition(cur, def) {
+			continue
+		}
+		if err := ioc.Rebind(def); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		watched[def.Name] = struct{}{}
+	}
+	for beanName := range watched {
+		if _, exist := loaded[beanName]; !exist {
+			bc.unregister(beanName)
+			delete(watched, beanName)
+		}
+	}
+}
+
+// isSameBeanDefinition 判断两个 bean 定义是否相同，函数类型的字段按函数地址比较
+func isSameBeanDefinition(a, b BeanDefinition) bool {
+	return a.Name == b.Name &&
+		a.Type == b.Type &&
+		a.Scope == b.Scope &&
+		reflect.DeepEqual(a.Qualifier, b.Qualifier) &&
+		reflect.DeepEqual(a.FieldQualifiers, b.FieldQualifiers) &&
+		a.InitMethod == b.InitMethod &&
+		a.DestroyMethod == b.DestroyMethod &&
+		a.DestroyPriority == b.DestroyPriority &&
+		a.Primary == b.Primary &&
+		reflect.DeepEqual(a.Properties, b.Properties) &&
+		isSameFunc(a.Constructor, b.Constructor) &&
+		isSameFunc(a.Supplier, b.Supplier) &&
+		reflect.DeepEqual(a.ConstructorArgs, b.ConstructorArgs)
+}
+
+// isSameFunc 判断两个函数是否相同
+func isSameFunc(a, b interface{}) bool {
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if !av.IsValid() || !bv.IsValid() || av.IsNil() || bv.IsNil() {
+		return (!av.IsValid() || av.IsNil()) == (!bv.IsValid() || bv.IsNil())
+	}
+	return av.Pointer() == bv.Pointer()
+}
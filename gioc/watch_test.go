@@ -0,0 +1,54 @@
+package gioc
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type watchedBean struct{}
+
+// staticSource 每次加载都返回相同的 bean 定义
+type staticSource struct {
+	mu   sync.Mutex
+	defs []BeanDefinition
+}
+
+func (s *staticSource) LoadDefinitions() ([]BeanDefinition, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.defs, nil
+}
+
+func newWatchedSource() *staticSource {
+	return &staticSource{defs: []BeanDefinition{{Name: "watched", Type: reflect.TypeOf((*watchedBean)(nil)), Scope: Singleton}}}
+}
+
+func TestWatchConfigRegistersDefinitions(t *testing.T) {
+	ioc := NewIOC()
+	defer ioc.Close()
+	source := newWatchedSource()
+	ioc.WatchConfig(source, time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for ioc.GetBean("watched") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("watched bean was not registered")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWatchConfigDoesNotModifyFrozenContainer(t *testing.T) {
+	ioc, err := NewBuilder().Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ioc.Close()
+	source := newWatchedSource()
+	ioc.WatchConfig(source, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	if _, ok := ioc.LookupBean("watched"); ok {
+		t.Fatal("frozen container was modified by WatchConfig")
+	}
+}
@@ -6,21 +6,21 @@ import (
 )
 
 type A struct {
-	B *B `di:"s" beanName:"bbbb"`
+	B *B `di:"bbbb"`
 }
 
 type B struct {
 	name string
 	age  int
-	C    *C `di:"p"`
-	A    *A `beanName:"a" di:"s"`
+	C    *C `di:"" scope:"p"`
+	A    *A `di:"a"`
 }
 
 type C struct {
 	i    int
 	b    bool
 	name string
-	A    A `beanName:"a" di:"s"`
+	A    A `di:"a"`
 }
 
 func main() {
@@ -50,7 +50,7 @@ func main() {
 	//fmt.Println(bean3 == bean.B) // true
 
 	// C 是原型的，所以不会存储，所以这里会创建一个新的 C，因此跟单例 bean B 中的 C 不一样，输出 false
-	// 如果将 C 改成 di:"s"，那么这里输出 true
+	// 如果将 C 改成 scope:"s"，那么这里输出 true
 	//bean4 := ioc.GetBean("C").(*C)
 	//fmt.Println(bean4 == bean3.C) // false
 }